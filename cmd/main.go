@@ -1,14 +1,162 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"github.com/Marian2701/CodingExercise/internal"
+	"github.com/Marian2701/CodingExercise/internal/auth"
+	"github.com/Marian2701/CodingExercise/internal/league"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/Marian2701/CodingExercise/internal/stats"
+	"github.com/Marian2701/CodingExercise/internal/tournament"
+	"log"
+	"os"
 )
 
 func main() {
-	scoreBoard := internal.NewScoreBoard()
-	scoreBase := internal.NewScoreBase()
+	dataDir := flag.String("data-dir", "", "directory for storing active and finished games, kept in memory only when empty")
+	sqlitePath := flag.String("sqlite", "", "SQLite database file for storing active and finished games, takes precedence over -data-dir")
+	compactionInterval := flag.Duration("compaction-interval", internal.DefaultCompactionInterval, "interval of compacting the stored games into snapshots")
+	leaguePoints := flag.String("league-points", league.ThreePointsForWin.String(), "points for a win, a draw and a loss in the league table")
+	templatesDir := flag.String("templates-dir", "", "directory to reload the HTML templates from on every request while developing them, the embedded templates are used when empty")
+	teamsPath := flag.String("teams", "", "YAML or JSON config file of the teams games can be played by, the built-in countries when empty")
+	usersPath := flag.String("users", "", "YAML or JSON config file of the users signing in to change matches, everyone may change them when empty")
+	tournamentPath := flag.String("tournament", "", "YAML or JSON config file of the groups of a tournament whose standings and knockout bracket are served, no tournament when empty")
+	leagueTieBreakers := flag.String("league-tie-breakers", "goal_difference,goals_for", "comma separated tie-breakers of teams level on points in the league table")
+	flag.Parse()
 
-	app := internal.NewApp(scoreBase, scoreBoard)
+	var teams internal.TeamStoring = models.Teams()
+	if *teamsPath != "" {
+		fileTeams, err := internal.NewFileTeams(*teamsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		models.SetTeams(fileTeams.Registry())
+		teams = fileTeams
+	}
+
+	pointsRule, err := league.ParsePointsRule(*leaguePoints)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tieBreakers, err := league.ParseTieBreakers(*leagueTieBreakers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	leagueTable, err := league.New(pointsRule, tieBreakers...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	teamStats := stats.NewIndex()
+	recorders := []internal.GameRecorder{leagueTable, teamStats}
+	var cup *tournament.Tournament
+	if *tournamentPath != "" {
+		cup, err = tournament.Load(*tournamentPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		recorders = append(recorders, cup)
+	}
+
+	ctx := context.Background()
+
+	var board internal.GameBoard = internal.NewScoreBoard()
+	var store internal.ScoreBaseStoring = internal.NewScoreBase()
+	var finisher internal.GameFinisher
+	var auditLog internal.AuditLog = internal.NewMemoryAuditLog()
+	var fixtures internal.FixtureStoring = internal.NewMemoryFixtures()
+	if *sqlitePath != "" {
+		sqlStore, err := internal.NewSQLStore(*sqlitePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		board = sqlStore.Board()
+		store = sqlStore.ScoreBase()
+		finisher = sqlStore
+		auditLog = sqlStore.AuditLog()
+		fixtures = sqlStore.Fixtures()
+	} else if *dataDir != "" {
+		fileBoard, err := internal.NewFileScoreBoard(*dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go fileBoard.RunCompaction(ctx, *compactionInterval)
+		board = fileBoard
+
+		fileStore, err := internal.NewFileScoreBase(*dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go fileStore.RunCompaction(ctx, *compactionInterval)
+		store = fileStore
+
+		fileAuditLog, err := internal.NewFileAuditLog(*dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		auditLog = fileAuditLog
+
+		fileFixtures, err := internal.NewFileFixtures(*dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fixtures = fileFixtures
+	}
+
+	events := internal.NewEventBroker(internal.DefaultEventHistory)
+	scoreBoard := internal.NewObservedBoard(board, events)
+	scoreBase := internal.NewObservedScoreBase(internal.NewRecordingScoreBase(store, recorders...), events)
+
+	if finisher != nil {
+		finisher = internal.NewObservedFinisher(internal.NewRecordingFinisher(finisher, recorders...), events)
+	} else if *dataDir != "" {
+		fileFinisher, err := internal.NewFileFinishService(scoreBoard, scoreBase, *dataDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go fileFinisher.RunRecovery(ctx, internal.FinishRecoveryInterval)
+		finisher = fileFinisher
+	} else {
+		finishService := internal.NewFinishService(scoreBoard, scoreBase)
+		go finishService.RunRecovery(ctx, internal.FinishRecoveryInterval)
+		finisher = finishService
+	}
+
+	hub := internal.NewHub(events)
+	go hub.Run(ctx)
+
+	scheduler := internal.NewScheduler(fixtures, scoreBoard, internal.SystemClock{})
+	go scheduler.Run(ctx)
+
+	opts := []internal.Option{
+		internal.WithEvents(events),
+		internal.WithHub(hub),
+		internal.WithFinisher(finisher),
+		internal.WithAuditLog(auditLog),
+		internal.WithScheduler(scheduler),
+		internal.WithLeague(leagueTable),
+		internal.WithStats(teamStats),
+		internal.WithTeams(teams),
+	}
+	if *templatesDir != "" {
+		templates, err := internal.NewTemplates(os.DirFS(*templatesDir), true)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, internal.WithTemplates(templates))
+	}
+	if cup != nil {
+		opts = append(opts, internal.WithTournament(cup))
+	}
+	if *usersPath != "" {
+		authenticator, err := auth.Load(*usersPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, internal.WithAuth(authenticator))
+	}
+
+	app := internal.NewApp(scoreBase, scoreBoard, opts...)
 	app.InitRoutes()
 	app.RunServer()
 }
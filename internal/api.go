@@ -0,0 +1,612 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/auth"
+	"github.com/Marian2701/CodingExercise/internal/league"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/Marian2701/CodingExercise/internal/tournament"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// apiPrefix is the path prefix of the versioned JSON API.
+const apiPrefix = "/api/v1"
+
+// startMatchRequest defines the JSON body accepted when starting a new match.
+// A scheduled match is put on the board without kicking it off.
+type startMatchRequest struct {
+	HomeTeam  string `json:"home_team"`
+	AwayTeam  string `json:"away_team"`
+	Scheduled bool   `json:"scheduled"`
+}
+
+// updateScoreRequest defines the JSON body accepted when updating the score of a match.
+// Pointers are used to tell a missing score apart from a zero score.
+// The acting operator is taken from the X-Actor header.
+type updateScoreRequest struct {
+	HomeScore *uint  `json:"home_score"`
+	AwayScore *uint  `json:"away_score"`
+	Reason    string `json:"reason"`
+}
+
+// updateShootoutRequest defines the JSON body accepted when updating the penalty shootout score of a match.
+type updateShootoutRequest struct {
+	HomePenalties *uint `json:"home_penalties"`
+	AwayPenalties *uint `json:"away_penalties"`
+}
+
+// undoRequest defines the JSON body accepted when undoing score changes of a match. Count defaults to one change.
+type undoRequest struct {
+	Count  int    `json:"count"`
+	Reason string `json:"reason"`
+}
+
+// recordGoalRequest defines the JSON body accepted when recording a goal of a match.
+// Type defaults to a regular goal.
+type recordGoalRequest struct {
+	Minute uint   `json:"minute"`
+	Team   string `json:"team"`
+	Player string `json:"player"`
+	Type   string `json:"type"`
+}
+
+// addFixtureRequest defines the JSON body accepted when scheduling a fixture. KickoffAt is an RFC 3339 time.
+type addFixtureRequest struct {
+	HomeTeam  string    `json:"home_team"`
+	AwayTeam  string    `json:"away_team"`
+	KickoffAt time.Time `json:"kickoff_at"`
+	Venue     string    `json:"venue"`
+}
+
+// leagueResponse defines the JSON body returned for the league table.
+type leagueResponse struct {
+	Points league.PointsRule `json:"points"`
+	Rows   []league.Row      `json:"rows"`
+}
+
+// tournamentResponse defines the JSON body returned for the tournament: the round-robin fixtures and the standings
+// of the groups, and the knockout bracket, null until it is seeded.
+type tournamentResponse struct {
+	Fixtures  []tournament.Pairing `json:"fixtures"`
+	Standings []tournament.Table   `json:"standings"`
+	Knockout  *tournament.Bracket  `json:"knockout"`
+}
+
+// seedKnockoutRequest defines the JSON body accepted when seeding the knockout stage of the tournament.
+// After is the id of the last game played before the knockout stage, only later games decide the knockout ties.
+type seedKnockoutRequest struct {
+	After uint32 `json:"after"`
+}
+
+// matchResponse defines the JSON body returned for a single match, live on the board or finished,
+// with the audit history of its score. Fixture is the fixture the match was started from at kickoff, if any.
+type matchResponse struct {
+	Game    *models.Game    `json:"game"`
+	Live    bool            `json:"live"`
+	Fixture *models.Fixture `json:"fixture,omitempty"`
+	History []AuditEntry    `json:"history"`
+}
+
+// errorResponse defines the JSON body returned for every failed API request.
+// Suggestions are the teams with names similar to a team name that matched no team.
+type errorResponse struct {
+	Error       string             `json:"error"`
+	Suggestions []models.Countries `json:"suggestions,omitempty"`
+}
+
+// initAPIRoutes registers the JSON API handlers on the provided mux.
+// The handlers drive the same GameBoard and ScoreBaseStoring as the HTML form handlers.
+func (a *App) initAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/matches", func(w http.ResponseWriter, r *http.Request) {
+		a.writeJSON(w, http.StatusOK, nonNilGames(a.board.GetGames()))
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/matches", a.requireRole(auth.RoleScorekeeper, func(w http.ResponseWriter, r *http.Request) {
+		var req startMatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode start match request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		add := a.board.StartGame
+		if req.Scheduled {
+			add = a.board.ScheduleGame
+		}
+		game, err := add(req.HomeTeam, req.AwayTeam)
+		if err != nil {
+			a.writeGameError(w, "failed to init game: ", err)
+			return
+		}
+		a.assignStarter(r, game)
+
+		a.writeJSON(w, http.StatusCreated, game)
+	}))
+
+	mux.HandleFunc("GET "+apiPrefix+"/matches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		game, live, err := a.getMatch(id)
+		if err != nil {
+			a.writeGameError(w, "failed to get match: ", err)
+			return
+		}
+		history, err := a.auditor.History(id)
+		if err != nil {
+			a.writeGameError(w, "failed to get score history: ", err)
+			return
+		}
+		if history == nil {
+			history = []AuditEntry{}
+		}
+		fixture, err := a.matchFixture(id)
+		if err != nil {
+			a.writeGameError(w, "failed to get fixture: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, matchResponse{Game: game, Live: live, Fixture: fixture, History: history})
+	})
+
+	mux.HandleFunc("PATCH "+apiPrefix+"/matches/{id}", a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		var req updateScoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode update score request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.HomeScore == nil || req.AwayScore == nil {
+			a.writeError(w, http.StatusBadRequest, "home_score and away_score are required")
+			return
+		}
+
+		change := scoreChangeFromRequest(r)
+		change.Reason = req.Reason
+		game, err := a.auditor.UpdateGame(id, *req.HomeScore, *req.AwayScore, change)
+		if err != nil {
+			a.writeGameError(w, "failed to update game on scoreBoard: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, game)
+	}))
+
+	mux.HandleFunc("PATCH "+apiPrefix+"/matches/{id}/shootout", a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		var req updateShootoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode update shootout request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.HomePenalties == nil || req.AwayPenalties == nil {
+			a.writeError(w, http.StatusBadRequest, "home_penalties and away_penalties are required")
+			return
+		}
+
+		game, err := a.board.UpdateShootout(id, *req.HomePenalties, *req.AwayPenalties)
+		if err != nil {
+			a.writeGameError(w, "failed to update shootout on scoreBoard: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, game)
+	}))
+
+	mux.HandleFunc("GET "+apiPrefix+"/matches/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		history, err := a.auditor.History(id)
+		if err != nil {
+			a.writeGameError(w, "failed to get score history: ", err)
+			return
+		}
+		if history == nil {
+			history = []AuditEntry{}
+		}
+
+		a.writeJSON(w, http.StatusOK, history)
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/matches/{id}/undo", a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		req := undoRequest{Count: 1}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			a.logger.Println("failed to decode undo request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Count < 1 {
+			a.writeError(w, http.StatusBadRequest, "count must be positive")
+			return
+		}
+
+		change := scoreChangeFromRequest(r)
+		change.Reason = req.Reason
+		game, err := a.auditor.Undo(id, req.Count, change)
+		if err != nil {
+			a.writeGameError(w, "failed to undo score changes: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, game)
+	}))
+
+	mux.HandleFunc("POST "+apiPrefix+"/matches/{id}/goals", a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		var req recordGoalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode record goal request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		game, err := a.board.GetGame(id)
+		if err != nil {
+			a.writeGameError(w, "failed to get game from scoreBoard: ", err)
+			return
+		}
+		goal := newGoal(game, req.Minute, req.Team, req.Player, req.Type)
+
+		game, err = a.board.RecordGoal(id, goal)
+		if err != nil {
+			a.writeGameError(w, "failed to record goal on scoreBoard: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusCreated, game)
+	}))
+
+	for _, transition := range models.AllTransitions {
+		mux.HandleFunc("POST "+apiPrefix+"/matches/{id}/"+string(transition), a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+			id, ok := a.matchIdFromPath(w, r)
+			if !ok {
+				return
+			}
+
+			game, err := a.board.TransitionGame(id, transition)
+			if err != nil {
+				a.writeGameError(w, "failed to transition game on scoreBoard: ", err)
+				return
+			}
+
+			a.writeJSON(w, http.StatusOK, game)
+		}))
+	}
+
+	mux.HandleFunc("POST "+apiPrefix+"/matches/{id}/finish", a.requireMatch(pathMatchId, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		game, err := a.finisher.FinishGame(id)
+		if err != nil {
+			a.writeGameError(w, "failed to finish game: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, game)
+	}))
+
+	mux.HandleFunc("GET "+apiPrefix+"/summary", func(w http.ResponseWriter, r *http.Request) {
+		query, err := summaryQueryFromRequest(r)
+		if err != nil {
+			a.logger.Println("failed to get summary query from request: ", err)
+			a.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		page, err := a.store.Query(query)
+		if err != nil {
+			a.writeGameError(w, "failed to query scoreBase: ", err)
+			return
+		}
+
+		if page.Next != 0 {
+			next := r.URL.Query()
+			next.Set("after", strconv.FormatUint(uint64(page.Next), 10))
+			w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+		}
+		a.writeJSON(w, http.StatusOK, nonNilGames(page.Games))
+	})
+
+	if a.scheduler != nil {
+		a.initFixtureRoutes(mux)
+	}
+
+	if a.stats != nil {
+		a.initStatsRoutes(mux)
+	}
+
+	if a.teams != nil {
+		a.initTeamRoutes(mux)
+	}
+
+	if a.tournament != nil {
+		a.initTournamentRoutes(mux)
+	}
+
+	if a.league != nil {
+		mux.HandleFunc("GET "+apiPrefix+"/league", func(w http.ResponseWriter, r *http.Request) {
+			a.writeJSON(w, http.StatusOK, leagueResponse{
+				Points: a.league.Rule(),
+				Rows:   a.league.Standings(),
+			})
+		})
+	}
+}
+
+// initTournamentRoutes registers the JSON API handlers of the group standings and the knockout bracket
+// of the tournament on the provided mux.
+func (a *App) initTournamentRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/tournament", func(w http.ResponseWriter, r *http.Request) {
+		a.writeJSON(w, http.StatusOK, tournamentResponse{
+			Fixtures:  a.tournament.Fixtures(),
+			Standings: a.tournament.Standings(),
+			Knockout:  a.tournament.Knockout(),
+		})
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/tournament/knockout", a.requireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		var req seedKnockoutRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode seed knockout request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		bracket, err := a.tournament.SeedKnockout(req.After)
+		if err != nil {
+			a.writeGameError(w, "failed to seed knockout: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusCreated, bracket)
+	}))
+}
+
+// initFixtureRoutes registers the JSON API handlers of the fixtures kept by the scheduler on the provided mux.
+func (a *App) initFixtureRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/fixtures", func(w http.ResponseWriter, r *http.Request) {
+		fixtures, err := a.scheduler.Upcoming()
+		if err != nil {
+			a.writeGameError(w, "failed to get fixtures: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, nonNilFixtures(fixtures))
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/fixtures", a.requireRole(auth.RoleScorekeeper, func(w http.ResponseWriter, r *http.Request) {
+		var req addFixtureRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode add fixture request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		fixture, err := a.scheduler.AddFixture(req.HomeTeam, req.AwayTeam, req.KickoffAt, req.Venue)
+		if err != nil {
+			a.writeGameError(w, "failed to add fixture: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusCreated, fixture)
+	}))
+}
+
+// initTeamRoutes registers the JSON API handlers listing, adding and retiring teams on the provided mux.
+func (a *App) initTeamRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/teams", func(w http.ResponseWriter, r *http.Request) {
+		a.writeJSON(w, http.StatusOK, a.teams.Teams())
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/teams", a.requireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		var req models.Team
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode add team request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		team, err := a.teams.AddTeam(req)
+		if err != nil {
+			a.writeGameError(w, "failed to add team: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusCreated, team)
+	}))
+
+	mux.HandleFunc("POST "+apiPrefix+"/teams/{team}/retire", a.requireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		id := models.Countries(r.PathValue("team"))
+		if a.scheduler != nil {
+			scheduled, err := a.scheduler.Scheduled(id)
+			if err != nil {
+				a.writeGameError(w, "failed to get upcoming fixtures: ", err)
+				return
+			}
+			if scheduled {
+				a.writeGameError(w, "failed to retire team: ", fmt.Errorf("%w: %q", models.ErrTeamScheduled, id))
+				return
+			}
+		}
+
+		team, err := a.teams.RetireTeam(id)
+		if err != nil {
+			a.writeGameError(w, "failed to retire team: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, team)
+	}))
+}
+
+// initStatsRoutes registers the JSON API handlers of the team statistics on the provided mux.
+func (a *App) initStatsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/teams/{team}/stats", func(w http.ResponseWriter, r *http.Request) {
+		team, ok := a.teamFromPath(w, r, "team")
+		if !ok {
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, a.stats.Team(team))
+	})
+
+	mux.HandleFunc("GET "+apiPrefix+"/teams/{team}/head-to-head/{opponent}", func(w http.ResponseWriter, r *http.Request) {
+		team, ok := a.teamFromPath(w, r, "team")
+		if !ok {
+			return
+		}
+		opponent, ok := a.teamFromPath(w, r, "opponent")
+		if !ok {
+			return
+		}
+		if team == opponent {
+			a.writeError(w, http.StatusBadRequest, models.ErrSameTeam.Error())
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, a.stats.HeadToHead(team, opponent))
+	})
+}
+
+// summaryQueryFromRequest parses the limit, after, min_score and max_score query parameters of the request.
+func summaryQueryFromRequest(r *http.Request) (SummaryQuery, error) {
+	var query SummaryQuery
+	values := r.URL.Query()
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.ParseUint(limit, 10, 31)
+		if err != nil {
+			return SummaryQuery{}, errors.New("invalid limit")
+		}
+		query.Limit = int(n)
+	}
+	if after := values.Get("after"); after != "" {
+		id, err := strconv.ParseUint(after, 10, 32)
+		if err != nil {
+			return SummaryQuery{}, models.ErrInvalidCursor
+		}
+		query.After = uint32(id)
+	}
+	for name, bound := range map[string]**uint{"min_score": &query.MinScore, "max_score": &query.MaxScore} {
+		if value := values.Get(name); value != "" {
+			score, err := strconv.ParseUint(value, 10, strconv.IntSize)
+			if err != nil {
+				return SummaryQuery{}, fmt.Errorf("invalid %s", name)
+			}
+			*bound = new(uint)
+			**bound = uint(score)
+		}
+	}
+	return query, nil
+}
+
+// matchIdFromPath parses the {id} path value of the request.
+// On failure it writes a 400 response and returns false.
+func (a *App) matchIdFromPath(w http.ResponseWriter, r *http.Request) (uint32, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		a.logger.Println("failed to get id from request: ", err)
+		a.writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return uint32(id), true
+}
+
+// teamFromPath parses the path value with the provided name of the request as a country, active or retired.
+// On failure it writes a 400 response and returns false.
+func (a *App) teamFromPath(w http.ResponseWriter, r *http.Request, name string) (models.Countries, bool) {
+	team, err := models.ResolveAnyTeam(r.PathValue(name))
+	if err != nil {
+		a.writeGameError(w, "failed to resolve team: ", err)
+		return models.NotACountry, false
+	}
+	return team, true
+}
+
+// writeGameError maps errors returned by GameBoard and ScoreBaseStoring to API status codes.
+// Unknown errors are logged with the provided message and reported as internal errors.
+func (a *App) writeGameError(w http.ResponseWriter, logMessage string, err error) {
+	var unknownTeam *models.UnknownTeamError
+	switch {
+	case errors.As(err, &unknownTeam):
+		a.writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error(), Suggestions: unknownTeam.Suggestions})
+	case errors.Is(err, models.ErrGameNotFound), errors.Is(err, models.ErrFixtureNotFound), errors.Is(err, models.ErrTeamNotFound):
+		a.writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrInvalidCountry), errors.Is(err, models.ErrInvalidCursor), errors.Is(err, models.ErrInvalidGoal):
+		a.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrUnknownTransition), errors.Is(err, models.ErrInvalidKickoff), errors.Is(err, models.ErrInvalidTeam):
+		a.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, auth.ErrUnknownUser), errors.Is(err, auth.ErrNotScorekeeper):
+		a.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrNothingToUndo), errors.Is(err, models.ErrScoreChanged),
+		errors.Is(err, models.ErrInvalidTransition), errors.Is(err, models.ErrScoreUpdateNotAllowed),
+		errors.Is(err, models.ErrShootoutUndecided), errors.Is(err, models.ErrSameTeam),
+		errors.Is(err, models.ErrTeamAlreadyPlaying), errors.Is(err, models.ErrDuplicateTeam),
+		errors.Is(err, models.ErrTeamScheduled), errors.Is(err, tournament.ErrInvalidBracket):
+		a.writeError(w, http.StatusConflict, err.Error())
+	default:
+		a.logger.Println(logMessage, err)
+		a.writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// writeError writes a JSON error body with the provided status code.
+func (a *App) writeError(w http.ResponseWriter, status int, message string) {
+	a.writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeJSON encodes the provided value as the JSON response body with the provided status code.
+func (a *App) writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		a.logger.Println("failed to encode response: ", err)
+	}
+}
+
+// nonNilGames makes sure an empty list of games is encoded as an empty JSON array instead of null.
+func nonNilGames(games []*models.Game) []*models.Game {
+	if games == nil {
+		return []*models.Game{}
+	}
+	return games
+}
+
+// nonNilFixtures makes sure an empty list of fixtures is encoded as an empty JSON array instead of null.
+func nonNilFixtures(fixtures []*models.Fixture) []*models.Fixture {
+	if fixtures == nil {
+		return []*models.Fixture{}
+	}
+	return fixtures
+}
@@ -0,0 +1,493 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestApp() *App {
+	app := NewApp(NewScoreBase(), NewScoreBoard())
+	app.InitRoutes()
+	return app
+}
+
+func doRequest(app *App, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestApi_StartMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Correct countries",
+			body:       `{"home_team": "Spain", "away_team": "Brazil"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Invalid country",
+			body:       `{"home_team": "Norway", "away_team": "Brazil"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid body",
+			body:       `{"home_team": `,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Same team",
+			body:       `{"home_team": "Italy", "away_team": "Italy"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Team already playing",
+			body:       `{"home_team": "Brazil", "away_team": "Germany", "scheduled": true}`,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	app := newTestApp()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, http.MethodPost, "/api/v1/matches", tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		})
+	}
+
+	rec := doRequest(app, http.MethodGet, "/api/v1/matches", "")
+	var games []*models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&games))
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, models.Spain, games[0].HomeTeam)
+}
+
+func TestApi_UpdateAndFinishMatch(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 2, "away_score": 1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var game models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&game))
+	assert.Equal(t, uint(2), game.HomeScore)
+	assert.Equal(t, uint(1), game.AwayScore)
+
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 2}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/summary", "")
+	var games []*models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&games))
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, uint32(1), games[0].Id)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches", "")
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestApi_WrongId(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Update not presented",
+			method:     http.MethodPatch,
+			target:     "/api/v1/matches/99",
+			body:       `{"home_score": 1, "away_score": 1}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Finish not presented",
+			method:     http.MethodPost,
+			target:     "/api/v1/matches/99/finish",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Not a number",
+			method:     http.MethodPost,
+			target:     "/api/v1/matches/abc/finish",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	app := newTestApp()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var body errorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.NotEmpty(t, body.Error)
+		})
+	}
+}
+
+func TestApi_SummaryPagination(t *testing.T) {
+	app := newTestApp()
+	for _, game := range queryTestData() {
+		assert.NoError(t, app.store.Insert(game))
+	}
+
+	rec := doRequest(app, http.MethodGet, "/api/v1/summary?limit=2&min_score=4", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `</api/v1/summary?after=2&limit=2&min_score=4>; rel="next"`, rec.Header().Get("Link"))
+	var games []*models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&games))
+	assert.Equal(t, []uint32{4, 2}, gameIds(games))
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/summary?after=2&limit=2&min_score=4", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	games = nil
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&games))
+	assert.Equal(t, []uint32{1, 5}, gameIds(games))
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/summary?after=5&limit=2&min_score=4", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Link"))
+
+	for _, target := range []string{"/api/v1/summary?after=99", "/api/v1/summary?limit=-1", "/api/v1/summary?max_score=abc"} {
+		rec = doRequest(app, http.MethodGet, target, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
+
+func TestApi_RecordGoal(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "Regular goal by default",
+			target:     "/api/v1/matches/1/goals",
+			body:       `{"minute": 23, "team": "Spain", "player": "Morata"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Penalty",
+			target:     "/api/v1/matches/1/goals",
+			body:       `{"minute": 88, "team": "Brazil", "player": "Neymar", "type": "penalty"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Team not playing",
+			target:     "/api/v1/matches/1/goals",
+			body:       `{"minute": 90, "team": "Italy", "player": "Rossi"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid body",
+			target:     "/api/v1/matches/1/goals",
+			body:       `{"minute": `,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Not presented",
+			target:     "/api/v1/matches/99/goals",
+			body:       `{"minute": 90, "team": "Spain", "player": "Morata"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, http.MethodPost, tt.target, tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches", "")
+	var games []*models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&games))
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, []models.Goal{
+		{Minute: 23, Team: models.Spain, Player: "Morata", Type: models.GoalRegular},
+		{Minute: 88, Team: models.Brazil, Player: "Neymar", Type: models.GoalPenalty},
+	}, games[0].Goals)
+
+	rec = doRequest(app, http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "88' Neymar (Brazil), penalty")
+}
+
+func TestApp_RecordGoal(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// The form records a regular goal without a type the same way as the JSON API.
+	values := url.Values{"matchIndex": {"1"}, "minute": {"23"}, "team": {"Spain"}, "player": {"Morata"}}
+	req := httptest.NewRequest(http.MethodPost, "/record_goal", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+
+	game, err := app.board.GetGame(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Goal{{Minute: 23, Team: models.Spain, Player: "Morata", Type: models.GoalRegular}}, game.Goals)
+}
+
+func TestApi_HistoryAndUndo(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/matches/1", strings.NewReader(`{"home_score": 11, "away_score": 0, "reason": "goal"}`))
+	req.Header.Set("X-Actor", "operator")
+	rec = httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/undo", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var game models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&game))
+	assert.Equal(t, uint(0), game.HomeScore)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/undo", `{"count": 1}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/undo", `{"count": 0}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches/1/history", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var history []AuditEntry
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&history))
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "operator", history[0].Actor)
+	assert.Equal(t, "goal", history[0].Reason)
+	assert.Equal(t, uint(11), history[0].NewHomeScore)
+	assert.Equal(t, defaultActor, history[1].Actor)
+	assert.Equal(t, []uint64{history[0].Seq}, history[1].Undoes)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches/99/history", "")
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestApi_Match(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 2, "away_score": 1, "reason": "late goal"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	tests := []struct {
+		name     string
+		finish   bool
+		wantLive bool
+	}{
+		{name: "Live", finish: false, wantLive: true},
+		{name: "Finished", finish: true, wantLive: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.finish {
+				rec := doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+				assert.Equal(t, http.StatusOK, rec.Code)
+			}
+
+			rec := doRequest(app, http.MethodGet, "/api/v1/matches/1", "")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var match matchResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&match))
+			assert.Equal(t, tt.wantLive, match.Live)
+			assert.Equal(t, uint(2), match.Game.HomeScore)
+			assert.Equal(t, 1, len(match.History))
+			assert.Equal(t, "late goal", match.History[0].Reason)
+
+			rec = doRequest(app, http.MethodGet, "/matches/1", "")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "<title>Spain - Brazil</title>")
+			assert.Contains(t, rec.Body.String(), "0 : 0 → 2 : 1 | anonymous | late goal")
+
+			rec = doRequest(app, http.MethodGet, "/", "")
+			assert.Contains(t, rec.Body.String(), `<a href="/matches/1">Match page</a>`)
+		})
+	}
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches/99", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doRequest(app, http.MethodGet, "/api/v1/matches/abc", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(app, http.MethodGet, "/matches/99", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doRequest(app, http.MethodGet, "/matches/abc", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestApi_MatchLifecycle(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil", "scheduled": true}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var game models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&game))
+	assert.Equal(t, models.StatusScheduled, game.Status)
+
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 1, "away_score": 0}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	for _, transition := range []string{"kickoff", "half_time", "resume", "extra_time", "shootout"} {
+		rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/"+transition, "")
+		assert.Equal(t, http.StatusOK, rec.Code, transition)
+	}
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/kickoff", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/99/kickoff", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1/shootout", `{"home_penalties": 4, "away_penalties": 5}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var finished map[string]any
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&finished))
+	assert.Equal(t, string(models.StatusFinished), finished["status"])
+	assert.Equal(t, string(models.Brazil), finished["winner"])
+	assert.Equal(t, map[string]any{"home": 4.0, "away": 5.0}, finished["penalty_score"])
+
+	rec = doRequest(app, http.MethodGet, "/", "")
+	assert.Contains(t, rec.Body.String(), "Spain - Brazil | 0 : 0 (regulation 0 : 0) (extra time 0 : 0) (penalties 4 : 5) | finished | winner: Brazil")
+}
+
+func TestApi_Fixtures(t *testing.T) {
+	board := NewScoreBoard()
+	clock := newFakeClock(time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC))
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+	app := NewApp(NewScoreBase(), board, WithScheduler(scheduler))
+	app.InitRoutes()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"valid fixture", `{"home_team": "Spain", "away_team": "Brazil", "kickoff_at": "2026-06-11T20:00:00Z", "venue": "Estadio Azteca"}`, http.StatusCreated},
+		{"invalid country", `{"home_team": "Spain", "away_team": "Narnia", "kickoff_at": "2026-06-11T20:00:00Z"}`, http.StatusBadRequest},
+		{"missing kickoff", `{"home_team": "Spain", "away_team": "Brazil"}`, http.StatusBadRequest},
+		{"invalid kickoff", `{"home_team": "Spain", "away_team": "Brazil", "kickoff_at": "tomorrow"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, http.MethodPost, "/api/v1/fixtures", tt.body)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+
+	rec := doRequest(app, http.MethodGet, "/api/v1/fixtures", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var fixtures []models.Fixture
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&fixtures))
+	assert.Equal(t, 1, len(fixtures))
+	assert.Equal(t, "Estadio Azteca", fixtures[0].Venue)
+
+	rec = doRequest(app, http.MethodGet, "/", "")
+	assert.Contains(t, rec.Body.String(), "2026-06-11 20:00 | Spain - Brazil | Estadio Azteca")
+
+	// The venue of the fixture is shown with the match it was promoted to, also once the match is finished.
+	clock.Advance(2 * time.Hour)
+	_, err := scheduler.PromoteDue()
+	assert.NoError(t, err)
+	for _, finish := range []bool{false, true} {
+		if finish {
+			rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+		rec = doRequest(app, http.MethodGet, "/api/v1/matches/1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var match matchResponse
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&match))
+		assert.Equal(t, "Estadio Azteca", match.Fixture.Venue)
+		rec = doRequest(app, http.MethodGet, "/matches/1", "")
+		assert.Contains(t, rec.Body.String(), "Venue: Estadio Azteca")
+	}
+}
+
+func TestApp_Locale(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	tests := []struct {
+		name           string
+		target         string
+		acceptLanguage string
+		want           []string
+		wantLanguage   string
+		wantCookie     bool
+	}{
+		{
+			name:         "English by default",
+			target:       "/",
+			want:         []string{`<html lang="en">`, "<h2>Active matches</h2>", "Spain - Brazil | 0 : 0 | live"},
+			wantLanguage: "en",
+		},
+		{
+			name:           "Russian from Accept-Language",
+			target:         "/",
+			acceptLanguage: "ru-RU,ru;q=0.9",
+			want:           []string{`<html lang="ru">`, "<h2>Текущие матчи</h2>", "Испания - Бразилия | 0 : 0 | идёт", `<option value="Spain">Испания</option>`},
+			wantLanguage:   "ru",
+		},
+		{
+			name:           "Query parameter over Accept-Language",
+			target:         "/?lang=en",
+			acceptLanguage: "ru",
+			want:           []string{`<html lang="en">`, "<h2>Active matches</h2>"},
+			wantLanguage:   "en",
+			wantCookie:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			app.Server.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			for _, want := range tt.want {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+			assert.Equal(t, tt.wantLanguage, rec.Header().Get("Content-Language"))
+			assert.Equal(t, tt.wantCookie, strings.Contains(rec.Header().Get("Set-Cookie"), "lang="+tt.wantLanguage))
+		})
+	}
+}
@@ -2,139 +2,299 @@ package internal
 
 import (
 	"errors"
+	"github.com/Marian2701/CodingExercise/internal/auth"
+	"github.com/Marian2701/CodingExercise/internal/i18n"
+	"github.com/Marian2701/CodingExercise/internal/league"
 	"github.com/Marian2701/CodingExercise/internal/models"
-	"html/template"
+	"github.com/Marian2701/CodingExercise/internal/stats"
+	"github.com/Marian2701/CodingExercise/internal/tournament"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 )
 
 // App defines the core struct for the application, containing store, game board, server, and logger instances.
 type App struct {
-	store  ScoreBaseStoring
-	board  GameBoard
-	Server *http.Server
-	logger *log.Logger
+	store      ScoreBaseStoring
+	board      GameBoard
+	finisher   GameFinisher
+	auditor    *ScoreAuditor
+	auditLog   AuditLog
+	events     *EventBroker
+	hub        *Hub
+	scheduler  *Scheduler
+	league     *league.Table
+	stats      *stats.Index
+	tournament *tournament.Tournament
+	teams      TeamStoring
+	templates  *Templates
+	auth       *auth.Authenticator
+	Server     *http.Server
+	logger     *log.Logger
 }
 
-// NewApp returns a new instance of App initialized with provided store, board, nil server, and logger.
-func NewApp(store ScoreBaseStoring, board GameBoard) *App {
-	return &App{
+// GameFinisher defines a method moving a game from the board to the store as a single operation.
+type GameFinisher interface {
+	FinishGame(id uint32) (*models.Game, error)
+}
+
+// Option configures optional parts of the App.
+type Option func(*App)
+
+// WithEvents enables the event stream endpoint fed by the provided broker.
+// The broker should be the one the board and the store publish their changes to.
+func WithEvents(events *EventBroker) Option {
+	return func(a *App) {
+		a.events = events
+	}
+}
+
+// WithHub enables the WebSocket endpoint connecting clients to the provided hub.
+// The hub has to be running for the clients to receive updates.
+func WithHub(hub *Hub) Option {
+	return func(a *App) {
+		a.hub = hub
+	}
+}
+
+// WithFinisher makes the App finish games with the provided finisher.
+// By default games are finished by a FinishService over the board and the store of the App.
+func WithFinisher(finisher GameFinisher) Option {
+	return func(a *App) {
+		a.finisher = finisher
+	}
+}
+
+// WithScheduler enables scheduling fixtures with the provided scheduler.
+// The scheduler has to be running for the fixtures to be promoted onto the board at kickoff.
+func WithScheduler(scheduler *Scheduler) Option {
+	return func(a *App) {
+		a.scheduler = scheduler
+	}
+}
+
+// WithLeague enables the league table pages serving the provided table.
+// The table should be the one the store and the finisher of the App record finished games in.
+func WithLeague(table *league.Table) Option {
+	return func(a *App) {
+		a.league = table
+	}
+}
+
+// WithStats enables the team statistics and head-to-head endpoints serving the provided index.
+// The index should be the one the store and the finisher of the App record finished games in.
+func WithStats(index *stats.Index) Option {
+	return func(a *App) {
+		a.stats = index
+	}
+}
+
+// WithTournament enables the endpoints serving the group standings and the knockout bracket of the provided tournament.
+// The tournament should be one of the recorders the store and the finisher of the App record finished games in.
+func WithTournament(t *tournament.Tournament) Option {
+	return func(a *App) {
+		a.tournament = t
+	}
+}
+
+// WithTeams enables the endpoints listing, adding and retiring the teams of the provided store.
+// The store should keep the registry in use, see models.SetTeams, so new games are validated against its teams.
+func WithTeams(teams TeamStoring) Option {
+	return func(a *App) {
+		a.teams = teams
+	}
+}
+
+// WithTemplates makes the App render its pages with the provided templates instead of the embedded ones,
+// e.g. with templates reloaded from disk while developing them.
+func WithTemplates(templates *Templates) Option {
+	return func(a *App) {
+		a.templates = templates
+	}
+}
+
+// WithAuth makes the App require the users of the provided authenticator to sign in for changing matches,
+// fixtures and teams, and for reading them too if the authenticator requires logging in.
+// By default everyone may change everything.
+func WithAuth(authenticator *auth.Authenticator) Option {
+	return func(a *App) {
+		a.auth = authenticator
+	}
+}
+
+// WithAuditLog makes the App record score changes in the provided audit log.
+// By default score changes are recorded in memory.
+func WithAuditLog(log AuditLog) Option {
+	return func(a *App) {
+		a.auditLog = log
+	}
+}
+
+// NewApp returns a new instance of App initialized with provided store, board, nil server, logger and options.
+func NewApp(store ScoreBaseStoring, board GameBoard, opts ...Option) *App {
+	a := &App{
 		store:  store,
 		board:  board,
 		Server: nil,
 		logger: log.New(os.Stdout, "", log.LstdFlags),
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.finisher == nil {
+		a.finisher = NewFinishService(board, store)
+	}
+	if a.auditLog == nil {
+		a.auditLog = NewMemoryAuditLog()
+	}
+	if a.templates == nil {
+		a.templates = EmbeddedTemplates()
+	}
+	a.auditor = NewScoreAuditor(board, a.auditLog)
+	return a
 }
 
-// PageData defines the structure containing lists of countries, active matches, and completed matches.
+// summaryPageSize is the number of completed matches shown on a single page.
+const summaryPageSize = 50
+
+// PageData defines the structure containing the teams that can play, active matches, and completed matches.
+// NextCompleted is the cursor of the following page of completed matches, zero if there are no more matches.
+// Transitions are offered for moving active matches through their lifecycle.
+// Scheduling reports whether fixtures can be scheduled, Fixtures are the upcoming ones.
+// League reports whether the league table is served.
+// Locale is the locale the page is shown in, Locales are the ones it can be switched to.
+// Auth reports whether users sign in, User is the signed in user, if any.
 type PageData struct {
-	Countries        []models.Countries
+	Locale           *i18n.Locale
+	Locales          []*i18n.Locale
+	Countries        []models.Team
 	ActiveMatches    []*models.Game
 	CompletedMatches []*models.Game
+	NextCompleted    uint32
+	Transitions      []models.Transition
+	Scheduling       bool
+	Fixtures         []*models.Fixture
+	League           bool
+	Auth             bool
+	User             auth.User
+}
+
+// LeagueData defines the structure containing the points rule and the rows of the league table
+// together with the locale the table is shown in and the ones it can be switched to.
+type LeagueData struct {
+	Locale  *i18n.Locale
+	Locales []*i18n.Locale
+	Rule    league.PointsRule
+	Rows    []league.Row
+}
+
+// MatchData defines the structure containing a single match, live on the board or finished, and the audit history
+// of its score, together with the locale the match is shown in and the ones it can be switched to.
+// Elapsed is the time since the kickoff of a live match that has kicked off, zero otherwise.
+// Fixture is the fixture the match was started from at kickoff, if any.
+type MatchData struct {
+	Locale  *i18n.Locale
+	Locales []*i18n.Locale
+	Game    *models.Game
+	Live    bool
+	Elapsed time.Duration
+	Fixture *models.Fixture
+	History []AuditEntry
 }
 
-// InitRoutes initializes HTTP routes for handling match selection, match updates, and game completion.
+// kickoffLayout is the layout of the kickoff time submitted by the datetime-local input of the fixture form.
+const kickoffLayout = "2006-01-02T15:04"
+
+// InitRoutes initializes HTTP routes for handling match selection, match updates, and game completion,
+// together with the JSON API routes and, when enabled, the event stream, WebSocket and login routes.
+// With an authenticator the changes are only accepted from signed in users allowed to make them, see WithAuth.
 func (a *App) InitRoutes() {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		data := PageData{
-			Countries:        models.AllCountries,
-			ActiveMatches:    a.board.GetGames(),
-			CompletedMatches: a.store.GetGames(),
-		}
-
-		tmpl, err := template.New("index").Parse(`
-			<!DOCTYPE html>
-			<html lang="ru">
-			<head>
-				<meta charset="UTF-8">
-				<title>Matches</title>
-			</head>
-			<body>
-				<h1>Selection of countries for the match</h1>
-				<form method="post" action="/start_game">
-					<label for="country1">First country:</label>
-					<select name="country1">
-						{{range .Countries}}
-							<option value="{{.}}">{{.}}</option>
-						{{end}}
-					</select>
-					<label for="country2">Second country:</label>
-					<select name="country2">
-						{{range .Countries}}
-							<option value="{{.}}">{{.}}</option>
-						{{end}}
-					</select>
-					<button type="submit">Start a match</button>
-				</form>
-
-				<h2>Active matches</h2>
-				<ul>
-					{{range .ActiveMatches}}
-						<li>
-							{{.HomeTeam}} - {{.AwayTeam}} | {{.HomeScore}} : {{.AwayScore}}
-							<form method="post" action="/update_score">
-								<input type="hidden" name="matchIndex" value="{{.Id}}">
-								<input type="number" name="score1" value="{{.HomeScore}}" min="0">
-								<input type="number" name="score2" value="{{.AwayScore}}" min="0">
-								<button type="submit">Update the result</button>
-							</form>
-							<form method="post" action="/end_game">
-								<input type="hidden" name="matchIndex" value="{{.Id}}">
-								<button type="submit">Finish match</button>
-							</form>
-						</li>
-					{{end}}
-				</ul>
-
-				<h2>Completed matches</h2>
-				<ul>
-					{{range .CompletedMatches}}
-						<li>{{.HomeTeam}} - {{.AwayTeam}} | {{.HomeScore}} : {{.AwayScore}}</li>
-					{{end}}
-				</ul>
-			</body>
-			</html>
-		`)
+		query := SummaryQuery{Limit: summaryPageSize}
+		if after := r.URL.Query().Get("after"); after != "" {
+			id, err := strconv.ParseUint(after, 10, 32)
+			if err != nil {
+				a.logger.Println("failed to get cursor from request: ", err)
+				http.Error(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			query.After = uint32(id)
+		}
 
+		completed, err := a.store.Query(query)
 		if err != nil {
-			a.logger.Println("failed to parse template: ", err)
+			if errors.Is(err, models.ErrInvalidCursor) {
+				http.Error(w, "Invalid cursor", http.StatusBadRequest)
+				return
+			}
+			a.logger.Println("failed to query scoreBase: ", err)
 			http.Error(w, "internal error", http.StatusInternalServerError)
 			return
 		}
 
-		err = tmpl.Execute(w, data)
-		if err != nil {
-			a.logger.Println("failed to execute template data: ", err)
+		data := PageData{
+			Locale:           a.localeFromRequest(w, r),
+			Locales:          i18n.Locales(),
+			Countries:        models.Teams().Active(),
+			ActiveMatches:    a.board.GetGames(),
+			CompletedMatches: completed.Games,
+			NextCompleted:    completed.Next,
+			Transitions:      models.AllTransitions,
+			Scheduling:       a.scheduler != nil,
+			League:           a.league != nil,
+			Auth:             a.auth != nil,
+		}
+		data.User, _ = auth.UserFromContext(r.Context())
+		if a.scheduler != nil {
+			if data.Fixtures, err = a.scheduler.Upcoming(); err != nil {
+				a.logger.Println("failed to get fixtures: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := a.templates.Execute(w, templatePageIndex, data.Locale, data); err != nil {
+			a.logger.Println("failed to execute template: ", err)
 			http.Error(w, "internal error", http.StatusInternalServerError)
 		}
 	})
 
-	mux.HandleFunc("/start_game", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/start_game", a.requireRole(auth.RoleScorekeeper, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 			return
 		}
 
-		if err := a.board.StartGame(r.FormValue("country1"), r.FormValue("country2")); err != nil {
+		add := a.board.StartGame
+		if r.FormValue("schedule") != "" {
+			add = a.board.ScheduleGame
+		}
+		game, err := add(r.FormValue("country1"), r.FormValue("country2"))
+		if err != nil {
 			if errors.Is(err, models.ErrInvalidCountry) {
 				a.logger.Println("invalid country from request: ", err)
 				http.Error(w, "Invalid country", http.StatusBadRequest)
 				return
+			} else if errors.Is(err, models.ErrSameTeam) || errors.Is(err, models.ErrTeamAlreadyPlaying) {
+				a.logger.Println("failed to init game: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
 			} else {
 				a.logger.Println("failed to init game: ", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
 				return
 			}
 		}
+		a.assignStarter(r, game)
 
 		http.Redirect(w, r, "/", http.StatusSeeOther)
-	})
+	}))
 
-	mux.HandleFunc("/end_game", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/end_game", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 			return
@@ -147,25 +307,26 @@ func (a *App) InitRoutes() {
 			return
 		}
 
-		game, err := a.board.RemoveGame(uint32(id))
-		if err != nil {
+		if _, err := a.finisher.FinishGame(uint32(id)); err != nil {
 			if errors.Is(err, models.ErrGameNotFound) {
 				a.logger.Println("invalid id from request: ", err)
 				http.Error(w, "Invalid id", http.StatusBadRequest)
 				return
+			} else if errors.Is(err, models.ErrInvalidTransition) || errors.Is(err, models.ErrShootoutUndecided) {
+				a.logger.Println("failed to finish game: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
 			} else {
-				a.logger.Println("failed to remove game from scoreBoard: ", err)
+				a.logger.Println("failed to finish game: ", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
 				return
 			}
 		}
 
-		a.store.Insert(game)
-
 		http.Redirect(w, r, "/", http.StatusSeeOther)
-	})
+	}))
 
-	mux.HandleFunc("/update_score", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/update_score", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 			return
@@ -178,24 +339,28 @@ func (a *App) InitRoutes() {
 		}
 
 		homeScore, err := strconv.Atoi(r.FormValue("score1"))
-		if err != nil {
+		if err != nil || homeScore < 0 {
 			a.logger.Println("failed to get home score from request: ", err)
 			http.Error(w, "Invalid home score", http.StatusBadRequest)
 			return
 		}
 
 		awayScore, err := strconv.Atoi(r.FormValue("score2"))
-		if err != nil {
+		if err != nil || awayScore < 0 {
 			a.logger.Println("failed to get away score from request: ", err)
 			http.Error(w, "Invalid away score", http.StatusBadRequest)
 			return
 		}
 
-		if err := a.board.UpdateGame(uint32(id), uint(homeScore), uint(awayScore)); err != nil {
+		if _, err := a.auditor.UpdateGame(uint32(id), uint(homeScore), uint(awayScore), scoreChangeFromRequest(r)); err != nil {
 			if errors.Is(err, models.ErrGameNotFound) {
 				a.logger.Println("invalid id from request: ", err)
 				http.Error(w, "Invalid id", http.StatusBadRequest)
 				return
+			} else if errors.Is(err, models.ErrScoreUpdateNotAllowed) {
+				a.logger.Println("failed to update game on scoreBoard: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
 			} else {
 				a.logger.Println("failed to update game on scoreBoard: ", err)
 				http.Error(w, "internal error", http.StatusInternalServerError)
@@ -204,12 +369,384 @@ func (a *App) InitRoutes() {
 		}
 
 		http.Redirect(w, r, "/", http.StatusSeeOther)
-	})
+	}))
+
+	mux.HandleFunc("/undo_score", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.Atoi(r.FormValue("matchIndex"))
+		if err != nil {
+			a.logger.Println("failed to get id from request: ", err)
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := a.auditor.Undo(uint32(id), 1, scoreChangeFromRequest(r)); err != nil {
+			if errors.Is(err, models.ErrGameNotFound) {
+				a.logger.Println("invalid id from request: ", err)
+				http.Error(w, "Invalid id", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrNothingToUndo) || errors.Is(err, models.ErrScoreChanged) ||
+				errors.Is(err, models.ErrScoreUpdateNotAllowed) {
+				a.logger.Println("failed to undo score correction: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else {
+				a.logger.Println("failed to undo score correction: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}))
+
+	mux.HandleFunc("/update_shootout", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.Atoi(r.FormValue("matchIndex"))
+		if err != nil {
+			a.logger.Println("failed to get id from request: ", err)
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+
+		homePenalties, err := strconv.Atoi(r.FormValue("penalties1"))
+		if err != nil || homePenalties < 0 {
+			a.logger.Println("failed to get home penalties from request: ", err)
+			http.Error(w, "Invalid home penalties", http.StatusBadRequest)
+			return
+		}
+
+		awayPenalties, err := strconv.Atoi(r.FormValue("penalties2"))
+		if err != nil || awayPenalties < 0 {
+			a.logger.Println("failed to get away penalties from request: ", err)
+			http.Error(w, "Invalid away penalties", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := a.board.UpdateShootout(uint32(id), uint(homePenalties), uint(awayPenalties)); err != nil {
+			if errors.Is(err, models.ErrGameNotFound) {
+				a.logger.Println("invalid id from request: ", err)
+				http.Error(w, "Invalid id", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrScoreUpdateNotAllowed) {
+				a.logger.Println("failed to update shootout on scoreBoard: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else {
+				a.logger.Println("failed to update shootout on scoreBoard: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}))
+
+	mux.HandleFunc("/transition_game", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.Atoi(r.FormValue("matchIndex"))
+		if err != nil {
+			a.logger.Println("failed to get id from request: ", err)
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+
+		if _, err := a.board.TransitionGame(uint32(id), models.Transition(r.FormValue("transition"))); err != nil {
+			if errors.Is(err, models.ErrGameNotFound) {
+				a.logger.Println("invalid id from request: ", err)
+				http.Error(w, "Invalid id", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrUnknownTransition) {
+				a.logger.Println("invalid transition from request: ", err)
+				http.Error(w, "Invalid transition", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrInvalidTransition) {
+				a.logger.Println("failed to transition game on scoreBoard: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else {
+				a.logger.Println("failed to transition game on scoreBoard: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}))
+
+	mux.HandleFunc("/record_goal", a.requireMatch(formMatchId, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := strconv.Atoi(r.FormValue("matchIndex"))
+		if err != nil {
+			a.logger.Println("failed to get id from request: ", err)
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+
+		minute, err := strconv.Atoi(r.FormValue("minute"))
+		if err != nil || minute < 0 {
+			a.logger.Println("failed to get minute from request: ", err)
+			http.Error(w, "Invalid minute", http.StatusBadRequest)
+			return
+		}
+
+		game, err := a.board.GetGame(uint32(id))
+		if err != nil {
+			if errors.Is(err, models.ErrGameNotFound) {
+				a.logger.Println("invalid id from request: ", err)
+				http.Error(w, "Invalid id", http.StatusBadRequest)
+				return
+			}
+			a.logger.Println("failed to get game from scoreBoard: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		goal := newGoal(game, uint(minute), r.FormValue("team"), r.FormValue("player"), r.FormValue("type"))
+		if _, err := a.board.RecordGoal(uint32(id), goal); err != nil {
+			if errors.Is(err, models.ErrGameNotFound) {
+				a.logger.Println("invalid id from request: ", err)
+				http.Error(w, "Invalid id", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrInvalidGoal) {
+				a.logger.Println("invalid goal from request: ", err)
+				http.Error(w, "Invalid goal", http.StatusBadRequest)
+				return
+			} else if errors.Is(err, models.ErrScoreUpdateNotAllowed) {
+				a.logger.Println("failed to record goal on scoreBoard: ", err)
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			} else {
+				a.logger.Println("failed to record goal on scoreBoard: ", err)
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	}))
+
+	mux.HandleFunc("GET /matches/{id}", a.handleMatch)
+
+	if a.league != nil {
+		mux.HandleFunc("GET /league", a.handleLeague)
+	}
+
+	if a.scheduler != nil {
+		mux.HandleFunc("/add_fixture", a.requireRole(auth.RoleScorekeeper, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+				return
+			}
+
+			kickoffAt, err := time.ParseInLocation(kickoffLayout, r.FormValue("kickoff"), time.Local)
+			if err != nil {
+				a.logger.Println("failed to get kickoff from request: ", err)
+				http.Error(w, "Invalid kickoff", http.StatusBadRequest)
+				return
+			}
+
+			if _, err := a.scheduler.AddFixture(r.FormValue("country1"), r.FormValue("country2"), kickoffAt, r.FormValue("venue")); err != nil {
+				if errors.Is(err, models.ErrInvalidCountry) {
+					a.logger.Println("invalid country from request: ", err)
+					http.Error(w, "Invalid country", http.StatusBadRequest)
+					return
+				} else if errors.Is(err, models.ErrSameTeam) {
+					a.logger.Println("failed to add fixture: ", err)
+					http.Error(w, err.Error(), http.StatusConflict)
+					return
+				} else {
+					a.logger.Println("failed to add fixture: ", err)
+					http.Error(w, "internal error", http.StatusInternalServerError)
+					return
+				}
+			}
+
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+		}))
+	}
+
+	a.initAPIRoutes(mux)
+
+	var handler http.Handler = mux
+	if a.auth != nil {
+		a.initAuthRoutes(mux)
+		handler = a.authenticate(mux)
+	}
+
+	if a.events != nil {
+		mux.HandleFunc("GET "+apiPrefix+"/events", a.handleEvents)
+	}
+	if a.hub != nil {
+		mux.HandleFunc("GET "+apiPrefix+"/ws", a.handleWebSocket)
+	}
 
 	a.Server = &http.Server{
 		Addr:    ":8080",
-		Handler: mux,
+		Handler: handler,
+	}
+}
+
+// handleLeague renders the league table.
+func (a *App) handleLeague(w http.ResponseWriter, r *http.Request) {
+	data := LeagueData{
+		Locale:  a.localeFromRequest(w, r),
+		Locales: i18n.Locales(),
+		Rule:    a.league.Rule(),
+		Rows:    a.league.Standings(),
+	}
+
+	if err := a.templates.Execute(w, templatePageLeague, data.Locale, data); err != nil {
+		a.logger.Println("failed to execute template: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// handleMatch renders the page of the match with the id of the path, live or finished.
+func (a *App) handleMatch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		a.logger.Println("failed to get id from request: ", err)
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	game, live, err := a.getMatch(uint32(id))
+	if err != nil {
+		if errors.Is(err, models.ErrGameNotFound) {
+			http.Error(w, "Match not found", http.StatusNotFound)
+			return
+		}
+		a.logger.Println("failed to get match: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	history, err := a.auditor.History(game.Id)
+	if err != nil {
+		a.logger.Println("failed to get score history: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	fixture, err := a.matchFixture(game.Id)
+	if err != nil {
+		a.logger.Println("failed to get fixture: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	data := MatchData{
+		Locale:  a.localeFromRequest(w, r),
+		Locales: i18n.Locales(),
+		Game:    game,
+		Live:    live,
+		Fixture: fixture,
+		History: history,
+	}
+	if live && game.Status != models.StatusScheduled {
+		data.Elapsed = time.Since(game.StartedAt)
+	}
+
+	if err := a.templates.Execute(w, templatePageMatch, data.Locale, data); err != nil {
+		a.logger.Println("failed to execute template: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// getMatch returns the game with the provided id from the board or, once it is finished, from the store,
+// and reports whether it is live on the board. models.ErrGameNotFound is returned if neither has the game.
+func (a *App) getMatch(id uint32) (*models.Game, bool, error) {
+	game, err := a.board.GetGame(id)
+	if err == nil {
+		return game, true, nil
+	}
+	if !errors.Is(err, models.ErrGameNotFound) {
+		return nil, false, err
+	}
+	game, err = a.store.GetGame(id)
+	if err != nil {
+		return nil, false, err
+	}
+	return game, false, nil
+}
+
+// newGoal returns the goal of the game described by a request, the same way for the form and the JSON API.
+// The team is matched against the teams of the game, and the type defaults to a regular goal.
+func newGoal(game *models.Game, minute uint, team, player, goalType string) models.Goal {
+	goal := models.Goal{
+		Minute: minute,
+		Team:   game.GameTeam(team),
+		Player: player,
+		Type:   models.GoalType(goalType),
+	}
+	if goal.Type == "" {
+		goal.Type = models.GoalRegular
+	}
+	return goal
+}
+
+// matchFixture returns the fixture the game with the provided id was started from at kickoff,
+// or nil if fixtures are not scheduled or the game was started right away.
+func (a *App) matchFixture(id uint32) (*models.Fixture, error) {
+	if a.scheduler == nil {
+		return nil, nil
+	}
+	fixture, err := a.scheduler.Promoted(id)
+	if errors.Is(err, models.ErrFixtureNotFound) {
+		return nil, nil
+	}
+	return fixture, err
+}
+
+// localeCookieMaxAge is the lifetime of the cookie remembering the locale selected by the query parameter.
+const localeCookieMaxAge = 365 * 24 * 60 * 60
+
+// localeFromRequest returns the locale of the request, see i18n.FromRequest. A locale selected by the query parameter
+// is remembered in a cookie, so the pages the forms redirect to are shown in it as well.
+func (a *App) localeFromRequest(w http.ResponseWriter, r *http.Request) *i18n.Locale {
+	locale := i18n.FromRequest(r)
+	if _, ok := i18n.Lookup(r.URL.Query().Get(i18n.QueryParam)); ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     i18n.CookieName,
+			Value:    locale.Lang(),
+			Path:     "/",
+			MaxAge:   localeCookieMaxAge,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	w.Header().Set("Content-Language", locale.Lang())
+	w.Header().Add("Vary", "Accept-Language, Cookie")
+	return locale
+}
+
+// defaultActor is the actor recorded for score changes of requests that do not name one.
+const defaultActor = "anonymous"
+
+// scoreChangeFromRequest returns who changes a score and why, taken from the signed in user, otherwise from
+// the X-Actor header or the actor form field, and from the reason form field.
+func scoreChangeFromRequest(r *http.Request) ScoreChange {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		return ScoreChange{Actor: user.Name, Reason: r.FormValue("reason")}
+	}
+	actor := r.Header.Get("X-Actor")
+	if actor == "" {
+		actor = r.FormValue("actor")
+	}
+	if actor == "" {
+		actor = defaultActor
 	}
+	return ScoreChange{Actor: actor, Reason: r.FormValue("reason")}
 }
 
 // RunServer starts the HTTP server and logs fatal errors in case of failure.
@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"sync"
+	"time"
+)
+
+// auditJournalName is the name of the journal files of FileAuditLog in the data directory.
+const auditJournalName = "audit"
+
+// AuditEntry represents a single score change of a game. Entries are never changed once they are appended,
+// an undo appends a new entry listing the sequence numbers of the entries it reverted in Undoes.
+type AuditEntry struct {
+	Seq          uint64    `json:"seq"`
+	MatchId      uint32    `json:"match_id"`
+	OldHomeScore uint      `json:"old_home_score"`
+	OldAwayScore uint      `json:"old_away_score"`
+	NewHomeScore uint      `json:"new_home_score"`
+	NewAwayScore uint      `json:"new_away_score"`
+	At           time.Time `json:"at"`
+	Actor        string    `json:"actor"`
+	Reason       string    `json:"reason"`
+	Undoes       []uint64  `json:"undoes,omitempty"`
+}
+
+// ScoreChange defines who changes a score and why.
+type ScoreChange struct {
+	Actor  string
+	Reason string
+}
+
+// AuditLog defines methods for keeping the audit entries of score changes.
+// Append assigns the next sequence number to the entry and returns the stored entry,
+// History returns the entries of the game with the provided id in the order they were appended.
+type AuditLog interface {
+	Append(entry AuditEntry) (AuditEntry, error)
+	History(matchId uint32) ([]AuditEntry, error)
+}
+
+// ScoreAuditor updates scores on a GameBoard and records every update in an AuditLog,
+// so mistyped scores can be traced and undone.
+type ScoreAuditor struct {
+	board GameBoard
+	log   AuditLog
+	lock  sync.Mutex
+	now   func() time.Time
+}
+
+// NewScoreAuditor returns a new instance of ScoreAuditor updating scores on the board and recording them in the log.
+func NewScoreAuditor(board GameBoard, log AuditLog) *ScoreAuditor {
+	return &ScoreAuditor{
+		board: board,
+		log:   log,
+		now:   time.Now,
+	}
+}
+
+// UpdateGame sets the scores of the game with the provided id and records the change.
+// If the change cannot be recorded, the previous score is restored, unless the score was changed otherwise since,
+// and the error is returned.
+func (x *ScoreAuditor) UpdateGame(id uint32, homeScore, awayScore uint, change ScoreChange) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.update(id, nil, homeScore, awayScore, change, nil)
+}
+
+// History returns all recorded score changes of the game with the provided id, oldest first.
+func (x *ScoreAuditor) History(id uint32) ([]AuditEntry, error) {
+	return x.log.History(id)
+}
+
+// Undo reverts the last count score changes of the live game with the provided id that were not undone yet,
+// restoring the score from before the earliest of them, and records the undo as a new change.
+// Undo entries themselves are not undone. If the score was changed otherwise since the last change,
+// e.g. by a goal, models.ErrScoreChanged is returned and nothing is reverted.
+func (x *ScoreAuditor) Undo(id uint32, count int, change ScoreChange) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	history, err := x.log.History(id)
+	if err != nil {
+		return nil, err
+	}
+	undone := make(map[uint64]bool)
+	for _, entry := range history {
+		for _, seq := range entry.Undoes {
+			undone[seq] = true
+		}
+	}
+	var targets []AuditEntry
+	for i := len(history) - 1; i >= 0 && len(targets) < count; i-- {
+		if entry := history[i]; len(entry.Undoes) == 0 && !undone[entry.Seq] {
+			targets = append(targets, entry)
+		}
+	}
+	if count < 1 || len(targets) < count {
+		return nil, models.ErrNothingToUndo
+	}
+
+	latest, earliest := targets[0], targets[len(targets)-1]
+	undoes := make([]uint64, 0, len(targets))
+	for _, entry := range targets {
+		undoes = append(undoes, entry.Seq)
+	}
+	expected := &models.Score{Home: latest.NewHomeScore, Away: latest.NewAwayScore}
+	return x.update(id, expected, earliest.OldHomeScore, earliest.OldAwayScore, change, undoes)
+}
+
+// update sets the scores of the game and appends the audit entry of the change. The lock must be held by the caller.
+func (x *ScoreAuditor) update(id uint32, expected *models.Score, homeScore, awayScore uint, change ScoreChange, undoes []uint64) (*models.Game, error) {
+	old, game, err := x.swapScore(id, expected, homeScore, awayScore)
+	if err != nil {
+		return nil, err
+	}
+	_, err = x.log.Append(AuditEntry{
+		MatchId:      id,
+		OldHomeScore: old.Home,
+		OldAwayScore: old.Away,
+		NewHomeScore: homeScore,
+		NewAwayScore: awayScore,
+		At:           x.now(),
+		Actor:        change.Actor,
+		Reason:       change.Reason,
+		Undoes:       undoes,
+	})
+	if err != nil {
+		updated := models.Score{Home: homeScore, Away: awayScore}
+		if _, restoreErr := x.board.CompareAndSwapScore(id, updated, old.Home, old.Away); restoreErr != nil {
+			return nil, fmt.Errorf("record score change: %w, restore score: %w", err, restoreErr)
+		}
+		return nil, fmt.Errorf("record score change: %w", err)
+	}
+	return game, nil
+}
+
+// swapScore sets the scores of the game and returns the score it replaced. The score is only replaced if the game
+// still has the expected one, otherwise models.ErrScoreChanged is returned. Without an expected score the current one
+// is replaced, read again if it was changed concurrently, e.g. by a goal, so the replaced score is always the actual one.
+func (x *ScoreAuditor) swapScore(id uint32, expected *models.Score, homeScore, awayScore uint) (models.Score, *models.Game, error) {
+	for {
+		old := expected
+		if old == nil {
+			current, err := x.board.GetGame(id)
+			if err != nil {
+				return models.Score{}, nil, err
+			}
+			old = &models.Score{Home: current.HomeScore, Away: current.AwayScore}
+		}
+		game, err := x.board.CompareAndSwapScore(id, *old, homeScore, awayScore)
+		if errors.Is(err, models.ErrScoreChanged) && expected == nil {
+			continue
+		}
+		return *old, game, err
+	}
+}
+
+// MemoryAuditLog keeps the audit entries in memory.
+type MemoryAuditLog struct {
+	lock    sync.RWMutex
+	lastSeq uint64
+	entries map[uint32][]AuditEntry
+}
+
+// NewMemoryAuditLog returns a new empty instance of MemoryAuditLog.
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{entries: make(map[uint32][]AuditEntry)}
+}
+
+// Append stores the entry with the next sequence number.
+func (x *MemoryAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.lastSeq++
+	entry.Seq = x.lastSeq
+	x.entries[entry.MatchId] = append(x.entries[entry.MatchId], entry)
+	return entry, nil
+}
+
+// History returns a copy of the entries of the game with the provided id.
+func (x *MemoryAuditLog) History(matchId uint32) ([]AuditEntry, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return append([]AuditEntry(nil), x.entries[matchId]...), nil
+}
+
+// restore stores the entry with its sequence number as it is. It is used to rebuild the log from persisted entries.
+func (x *MemoryAuditLog) restore(entry AuditEntry) {
+	x.lastSeq = max(x.lastSeq, entry.Seq)
+	x.entries[entry.MatchId] = append(x.entries[entry.MatchId], entry)
+}
+
+// FileAuditLog keeps the audit entries in memory and journals every entry before it is visible.
+// The journal is never compacted, since every entry is kept forever anyway.
+type FileAuditLog struct {
+	memory  *MemoryAuditLog
+	journal *journal
+	lock    sync.Mutex
+}
+
+// NewFileAuditLog opens the audit log stored in dir, restoring all entries.
+func NewFileAuditLog(dir string) (*FileAuditLog, error) {
+	memory := NewMemoryAuditLog()
+
+	var snapshot []AuditEntry
+	j, err := openJournal(dir, auditJournalName, &snapshot, func(data json.RawMessage) error {
+		var entry AuditEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		memory.restore(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range snapshot {
+		memory.restore(entry)
+	}
+
+	return &FileAuditLog{
+		memory:  memory,
+		journal: j,
+	}, nil
+}
+
+// Append journals the entry with the next sequence number and stores it in memory.
+func (x *FileAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.memory.lock.RLock()
+	entry.Seq = x.memory.lastSeq + 1
+	x.memory.lock.RUnlock()
+	if err := x.journal.append(entry); err != nil {
+		return AuditEntry{}, err
+	}
+
+	x.memory.lock.Lock()
+	defer x.memory.lock.Unlock()
+	x.memory.restore(entry)
+	return entry, nil
+}
+
+// History returns the entries of the game with the provided id.
+func (x *FileAuditLog) History(matchId uint32) ([]AuditEntry, error) {
+	return x.memory.History(matchId)
+}
+
+// Close closes the log file. The audit log must not be used afterwards.
+func (x *FileAuditLog) Close() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.journal.close()
+}
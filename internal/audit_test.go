@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"errors"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// assertAuditLog drives a ScoreAuditor over the log through corrections and undos and checks the recorded history.
+func assertAuditLog(t *testing.T, log AuditLog) {
+	board := NewScoreBoard()
+	auditor := NewScoreAuditor(board, log)
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	operator := ScoreChange{Actor: "operator", Reason: "goal"}
+	for _, score := range [][2]uint{{1, 0}, {2, 0}, {12, 0}} {
+		_, err := auditor.UpdateGame(game.Id, score[0], score[1], operator)
+		assert.NoError(t, err)
+	}
+	_, err = auditor.UpdateGame(99, 1, 1, operator)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	undone, err := auditor.Undo(game.Id, 1, ScoreChange{Actor: "supervisor", Reason: "typo"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), undone.HomeScore)
+
+	undone, err = auditor.Undo(game.Id, 2, ScoreChange{Actor: "supervisor"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), undone.HomeScore)
+
+	_, err = auditor.Undo(game.Id, 1, operator)
+	assert.ErrorIs(t, err, models.ErrNothingToUndo)
+
+	history, err := auditor.History(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(history))
+	for i, entry := range history {
+		assert.Equal(t, uint64(i+1), entry.Seq)
+		assert.Equal(t, game.Id, entry.MatchId)
+	}
+	assert.Equal(t, uint(2), history[2].OldHomeScore)
+	assert.Equal(t, uint(12), history[2].NewHomeScore)
+	assert.Equal(t, "operator", history[2].Actor)
+	assert.Equal(t, []uint64{3}, history[3].Undoes)
+	assert.Equal(t, "typo", history[3].Reason)
+	assert.Equal(t, []uint64{2, 1}, history[4].Undoes)
+	assert.Equal(t, uint(2), history[4].OldHomeScore)
+	assert.Equal(t, uint(0), history[4].NewHomeScore)
+
+	// A goal recorded after the last correction must not be reverted by an undo.
+	_, err = auditor.UpdateGame(game.Id, 1, 0, operator)
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Brazil, Player: "Neymar", Type: models.GoalRegular})
+	assert.NoError(t, err)
+	_, err = auditor.Undo(game.Id, 1, operator)
+	assert.ErrorIs(t, err, models.ErrScoreChanged)
+}
+
+func TestScoreAuditor_MemoryAuditLog(t *testing.T) {
+	assertAuditLog(t, NewMemoryAuditLog())
+}
+
+func TestScoreAuditor_FileAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	log, err := NewFileAuditLog(dir)
+	assert.NoError(t, err)
+	assertAuditLog(t, log)
+	want, err := log.History(1)
+	assert.NoError(t, err)
+	assert.NoError(t, log.Close())
+
+	reopened, err := NewFileAuditLog(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	got, err := reopened.History(1)
+	assert.NoError(t, err)
+	assert.Equal(t, len(want), len(got))
+	for i := range want {
+		assert.Equal(t, want[i].Seq, got[i].Seq)
+		assert.Equal(t, want[i].Undoes, got[i].Undoes)
+		assert.Equal(t, true, want[i].At.Equal(got[i].At))
+	}
+
+	entry, err := reopened.Append(AuditEntry{MatchId: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(want)+1), entry.Seq)
+}
+
+func TestScoreAuditor_SQLAuditLog(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	assertAuditLog(t, store.AuditLog())
+}
+
+// goalRacingBoard is a GameBoard recording a goal of the home team right after the first game is read,
+// as if the goal was recorded concurrently with a score correction.
+type goalRacingBoard struct {
+	*ScoreBoard
+	raced bool
+}
+
+func (x *goalRacingBoard) GetGame(id uint32) (*models.Game, error) {
+	game, err := x.ScoreBoard.GetGame(id)
+	if err == nil && !x.raced {
+		x.raced = true
+		_, err = x.ScoreBoard.RecordGoal(id, models.Goal{Minute: 10, Team: game.HomeTeam, Player: "Morata", Type: models.GoalRegular})
+	}
+	return game, err
+}
+
+// goalRacingAuditLog is an AuditLog recording a goal of the home team on the board and failing to append,
+// as if the goal was recorded concurrently with a score correction that cannot be recorded.
+type goalRacingAuditLog struct {
+	*MemoryAuditLog
+	board GameBoard
+}
+
+func (x *goalRacingAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	if _, err := x.board.RecordGoal(entry.MatchId, models.Goal{Minute: 80, Team: models.Spain, Player: "Morata", Type: models.GoalRegular}); err != nil {
+		return AuditEntry{}, err
+	}
+	return AuditEntry{}, errors.New("disk full")
+}
+
+func TestScoreAuditor_UpdateGame_GoalRecordedConcurrently(t *testing.T) {
+	board := &goalRacingBoard{ScoreBoard: NewScoreBoard()}
+	auditor := NewScoreAuditor(board, NewMemoryAuditLog())
+	game, err := board.ScoreBoard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	// The goal landed between reading the score and correcting it, the entry records the score it actually replaced.
+	_, err = auditor.UpdateGame(game.Id, 2, 2, ScoreChange{Actor: "operator"})
+	assert.NoError(t, err)
+	history, err := auditor.History(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, [2]uint{1, 0}, [2]uint{history[0].OldHomeScore, history[0].OldAwayScore})
+
+	// A goal recorded after a correction that cannot be recorded is not overwritten by restoring the score.
+	auditor = NewScoreAuditor(board, &goalRacingAuditLog{MemoryAuditLog: NewMemoryAuditLog(), board: board.ScoreBoard})
+	_, err = auditor.UpdateGame(game.Id, 3, 2, ScoreChange{Actor: "operator"})
+	assert.ErrorIs(t, err, models.ErrScoreChanged)
+	current, err := board.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]uint{4, 2}, [2]uint{current.HomeScore, current.AwayScore})
+}
+
+func TestApp_UpdateScore(t *testing.T) {
+	app := newTestApp()
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	tests := []struct {
+		name     string
+		values   url.Values
+		wantCode int
+	}{
+		{name: "Valid", values: url.Values{"matchIndex": {"1"}, "score1": {"2"}, "score2": {"1"}}, wantCode: http.StatusSeeOther},
+		{name: "Negative home score", values: url.Values{"matchIndex": {"1"}, "score1": {"-1"}, "score2": {"1"}}, wantCode: http.StatusBadRequest},
+		{name: "Negative away score", values: url.Values{"matchIndex": {"1"}, "score1": {"2"}, "score2": {"-1"}}, wantCode: http.StatusBadRequest},
+		{name: "Missing match", values: url.Values{"matchIndex": {"99"}, "score1": {"2"}, "score2": {"1"}}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/update_score", strings.NewReader(tt.values.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			app.Server.Handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+
+	game, err := app.board.GetGame(1)
+	assert.NoError(t, err)
+	assert.Equal(t, [2]uint{2, 1}, [2]uint{game.HomeScore, game.AwayScore})
+}
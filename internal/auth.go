@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Marian2701/CodingExercise/internal/auth"
+	"github.com/Marian2701/CodingExercise/internal/i18n"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// loginRequest defines the JSON body accepted when logging in to the API.
+type loginRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// scorekeepersRequest defines the JSON body accepted when assigning scorekeepers to a match,
+// and returned for the scorekeepers of a match.
+type scorekeepersRequest struct {
+	Scorekeepers []string `json:"scorekeepers"`
+}
+
+// LoginData defines the structure containing the login form together with the locale it is shown in
+// and the ones it can be switched to. Failed reports whether the previous login failed.
+type LoginData struct {
+	Locale  *i18n.Locale
+	Locales []*i18n.Locale
+	Failed  bool
+}
+
+// initAuthRoutes registers the handlers logging users in and out of the HTML UI and the API,
+// and the JSON API handlers of the scorekeepers assigned to matches, on the provided mux.
+func (a *App) initAuthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /login", func(w http.ResponseWriter, r *http.Request) {
+		data := LoginData{
+			Locale:  a.localeFromRequest(w, r),
+			Locales: i18n.Locales(),
+			Failed:  r.URL.Query().Has("failed"),
+		}
+
+		if err := a.templates.Execute(w, templatePageLogin, data.Locale, data); err != nil {
+			a.logger.Println("failed to execute template: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		session, err := a.auth.Login(r.FormValue("name"), r.FormValue("password"))
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				a.logger.Println("failed to log in: ", err)
+				http.Redirect(w, r, "/login?failed", http.StatusSeeOther)
+				return
+			}
+			a.logger.Println("failed to log in: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     auth.SessionCookieName,
+			Value:    session.Token,
+			Path:     "/",
+			Expires:  session.ExpiresAt,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	})
+
+	mux.HandleFunc("POST /logout", func(w http.ResponseWriter, r *http.Request) {
+		a.auth.Logout(auth.RequestToken(r))
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     auth.SessionCookieName,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/login", func(w http.ResponseWriter, r *http.Request) {
+		var req loginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode login request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		session, err := a.auth.Login(req.Name, req.Password)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				a.writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			a.writeGameError(w, "failed to log in: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, session)
+	})
+
+	mux.HandleFunc("POST "+apiPrefix+"/logout", func(w http.ResponseWriter, r *http.Request) {
+		a.auth.Logout(auth.RequestToken(r))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET "+apiPrefix+"/matches/{id}/scorekeepers", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, scorekeepersRequest{Scorekeepers: nonNilNames(a.auth.Scorekeepers(id))})
+	})
+
+	mux.HandleFunc("PUT "+apiPrefix+"/matches/{id}/scorekeepers", a.requireRole(auth.RoleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		id, ok := a.matchIdFromPath(w, r)
+		if !ok {
+			return
+		}
+
+		var req scorekeepersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			a.logger.Println("failed to decode scorekeepers request: ", err)
+			a.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if _, _, err := a.getMatch(id); err != nil {
+			a.writeGameError(w, "failed to get match: ", err)
+			return
+		}
+
+		if err := a.auth.Assign(id, req.Scorekeepers); err != nil {
+			a.writeGameError(w, "failed to assign scorekeepers: ", err)
+			return
+		}
+
+		a.writeJSON(w, http.StatusOK, scorekeepersRequest{Scorekeepers: nonNilNames(a.auth.Scorekeepers(id))})
+	}))
+}
+
+// authenticate wraps the handler, putting the user signed in by the request into its context, see auth.UserFromContext.
+// If the authenticator requires logging in to read, requests that are not signed in are only let through
+// to the login handlers.
+func (a *App) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := a.auth.AuthenticateRequest(r)
+		if ok {
+			r = r.WithContext(auth.WithUser(r.Context(), user))
+		} else if a.auth.RequireLogin() && r.URL.Path != "/login" && r.URL.Path != apiPrefix+"/login" {
+			a.unauthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireRole wraps the handler of a change only signed in users with the role may make.
+// Without an authenticator everyone may make every change and the handler is returned as it is.
+func (a *App) requireRole(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	if a.auth == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			a.unauthorized(w, r)
+			return
+		}
+		if !user.Role.Allows(role) {
+			a.forbidden(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireMatch wraps the handler of a change of the match with the id returned by matchId, which only admins and
+// the scorekeepers assigned to the match may make. A request without a valid id is left to the handler to reject.
+func (a *App) requireMatch(matchId func(r *http.Request) (uint32, bool), next http.HandlerFunc) http.HandlerFunc {
+	if a.auth == nil {
+		return next
+	}
+	return a.requireRole(auth.RoleScorekeeper, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := auth.UserFromContext(r.Context())
+		if id, ok := matchId(r); ok && !a.auth.MayUpdate(user, id) {
+			a.forbidden(w, r)
+			return
+		}
+		next(w, r)
+	})
+}
+
+// assignStarter assigns the scorekeeper who started the game to it, so they may keep its score.
+func (a *App) assignStarter(r *http.Request, game *models.Game) {
+	if a.auth == nil {
+		return
+	}
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		a.auth.AssignScorekeeper(game.Id, user)
+	}
+}
+
+// unauthorized rejects a request that is not signed in: API requests with a 401 response
+// and HTML requests with a redirect to the login page.
+func (a *App) unauthorized(w http.ResponseWriter, r *http.Request) {
+	if isAPIRequest(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		a.writeError(w, http.StatusUnauthorized, "login required")
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// forbidden rejects a request of a signed in user who may not make the change with a 403 response.
+func (a *App) forbidden(w http.ResponseWriter, r *http.Request) {
+	if isAPIRequest(r) {
+		a.writeError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+// isAPIRequest reports whether the request is one of the JSON API.
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, apiPrefix+"/")
+}
+
+// pathMatchId returns the {id} path value of the request and reports whether it is a valid match id.
+func pathMatchId(r *http.Request) (uint32, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	return uint32(id), err == nil
+}
+
+// formMatchId returns the matchIndex form value of the request and reports whether it is a valid match id.
+func formMatchId(r *http.Request) (uint32, bool) {
+	id, err := strconv.ParseUint(r.FormValue("matchIndex"), 10, 32)
+	return uint32(id), err == nil
+}
+
+// nonNilNames makes sure an empty list of names is encoded as an empty JSON array instead of null.
+func nonNilNames(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
@@ -0,0 +1,339 @@
+// Package auth authenticates the users of the scoreboard and authorizes what they may change. Users are local,
+// loaded from a config file with bcrypt-hashed passwords, and sign in either with a session started by logging in
+// or with an API token. Every user has a role: viewers only read, scorekeepers update the matches assigned to them
+// and admins change anything, including the assignments.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionCookieName is the name of the cookie keeping the session token of a user logged in from the HTML UI.
+	SessionCookieName = "session"
+	// SessionTTL is how long a session is valid after logging in.
+	SessionTTL = 12 * time.Hour
+	// sessionTokenSize is the number of random bytes of a session token.
+	sessionTokenSize = 32
+)
+
+// Role represents what a user may do. Every role may do whatever the roles ranked below it may.
+type Role string
+
+const (
+	RoleViewer      Role = "viewer"
+	RoleScorekeeper Role = "scorekeeper"
+	RoleAdmin       Role = "admin"
+)
+
+// roleRanks ranks the roles from the least to the most privileged.
+var roleRanks = map[Role]int{
+	RoleViewer:      1,
+	RoleScorekeeper: 2,
+	RoleAdmin:       3,
+}
+
+// Valid reports whether the role is one of the known roles.
+func (x Role) Valid() bool {
+	_, ok := roleRanks[x]
+	return ok
+}
+
+// Allows reports whether a user with the role may do what the required role may.
+func (x Role) Allows(required Role) bool {
+	return x.Valid() && roleRanks[x] >= roleRanks[required]
+}
+
+// User represents a local user. PasswordHash is the bcrypt hash of the password, a user without one cannot log in
+// and only signs in with API tokens. TokenHashes are the hex-encoded SHA-256 hashes of the API tokens
+// of the user, see HashToken, so the config file does not keep the tokens themselves.
+type User struct {
+	Name         string   `json:"name" yaml:"name"`
+	Role         Role     `json:"role" yaml:"role"`
+	PasswordHash string   `json:"password_hash,omitempty" yaml:"password_hash,omitempty"`
+	TokenHashes  []string `json:"token_hashes,omitempty" yaml:"token_hashes,omitempty"`
+}
+
+// Config represents the content of a user config file. With RequireLogin set only signed in users may read
+// the scoreboard, otherwise reading is open to everyone and only changes require signing in.
+type Config struct {
+	Users        []User `json:"users" yaml:"users"`
+	RequireLogin bool   `json:"require_login" yaml:"require_login"`
+}
+
+// Session represents a signed in user of the HTML UI or the API. Token is the secret the user presents
+// on the following requests, in the session cookie or as a bearer token.
+type Session struct {
+	Token     string    `json:"token"`
+	User      string    `json:"user"`
+	Role      Role      `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Authenticator represents the users of the scoreboard, their sessions and the assignments of scorekeepers to matches.
+// Sessions and assignments are kept in memory, so users log in again and admins assign scorekeepers again
+// after a restart. Authenticator is safe for concurrent use.
+type Authenticator struct {
+	users        map[string]User
+	tokens       map[string]string
+	requireLogin bool
+	sessions     map[string]Session
+	assignments  map[uint32][]string
+	now          func() time.Time
+	lock         sync.RWMutex
+}
+
+// dummyPasswordHash returns the hash compared with the password of an unknown user, so logging in takes as long
+// whether the user exists or not. It is generated on first use.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// New returns a new instance of Authenticator of the users of the config. Every user needs a unique name,
+// a valid role and a password or an API token, otherwise ErrInvalidUser or ErrDuplicateUser is returned.
+func New(config Config) (*Authenticator, error) {
+	x := &Authenticator{
+		users:        make(map[string]User, len(config.Users)),
+		tokens:       make(map[string]string),
+		requireLogin: config.RequireLogin,
+		sessions:     make(map[string]Session),
+		assignments:  make(map[uint32][]string),
+		now:          time.Now,
+	}
+	for _, user := range config.Users {
+		if user.Name == "" || !user.Role.Valid() || (user.PasswordHash == "" && len(user.TokenHashes) == 0) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidUser, user.Name)
+		}
+		if _, ok := x.users[user.Name]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateUser, user.Name)
+		}
+		for _, tokenHash := range user.TokenHashes {
+			tokenHash = strings.ToLower(tokenHash)
+			if _, ok := x.tokens[tokenHash]; ok {
+				return nil, fmt.Errorf("%w: %q shares an API token", ErrInvalidUser, user.Name)
+			}
+			x.tokens[tokenHash] = user.Name
+		}
+		x.users[user.Name] = user
+	}
+	return x, nil
+}
+
+// Load returns a new instance of Authenticator of the users of the config file at the provided path.
+// The file is JSON if its name ends with .json and YAML otherwise.
+func Load(path string) (*Authenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read user config: %w", err)
+	}
+	var config Config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode user config %s: %w", path, err)
+	}
+
+	x, err := New(config)
+	if err != nil {
+		return nil, fmt.Errorf("load user config %s: %w", path, err)
+	}
+	return x, nil
+}
+
+// HashToken returns the hex-encoded SHA-256 hash of the API token, as kept in the TokenHashes of a User.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequireLogin reports whether only signed in users may read the scoreboard.
+func (x *Authenticator) RequireLogin() bool {
+	return x.requireLogin
+}
+
+// Login checks the password of the user and starts a new session of the user valid for SessionTTL.
+// ErrInvalidCredentials is returned for an unknown user, a user without a password or a wrong password.
+func (x *Authenticator) Login(name, password string) (Session, error) {
+	x.lock.RLock()
+	user, ok := x.users[name]
+	x.lock.RUnlock()
+
+	if !ok || user.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return Session{}, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return Session{}, ErrInvalidCredentials
+	}
+
+	token := make([]byte, sessionTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return Session{}, fmt.Errorf("generate session token: %w", err)
+	}
+	session := Session{
+		Token:     hex.EncodeToString(token),
+		User:      user.Name,
+		Role:      user.Role,
+		ExpiresAt: x.now().Add(SessionTTL),
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.sessions[HashToken(session.Token)] = session
+	return session, nil
+}
+
+// Logout ends the session with the provided token. Nothing is done for an unknown token.
+func (x *Authenticator) Logout(token string) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	delete(x.sessions, HashToken(token))
+}
+
+// Authenticate returns the user presenting the token, either an API token or the token of a valid session,
+// and reports whether the token is known. Expired sessions are ended.
+func (x *Authenticator) Authenticate(token string) (User, bool) {
+	if token == "" {
+		return User{}, false
+	}
+	hash := HashToken(token)
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if name, ok := x.tokens[hash]; ok {
+		return x.users[name], true
+	}
+	session, ok := x.sessions[hash]
+	if !ok {
+		return User{}, false
+	}
+	if !x.now().Before(session.ExpiresAt) {
+		delete(x.sessions, hash)
+		return User{}, false
+	}
+	return x.users[session.User], true
+}
+
+// AuthenticateRequest returns the user of the request, authenticated by the bearer token of the Authorization header
+// or, without the header, by the session cookie, and reports whether the request is signed in.
+func (x *Authenticator) AuthenticateRequest(r *http.Request) (User, bool) {
+	return x.Authenticate(RequestToken(r))
+}
+
+// RequestToken returns the bearer token of the Authorization header of the request or, without the header,
+// the token of the session cookie.
+func RequestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	}
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// Assign makes the users the scorekeepers of the match with the provided id, replacing the ones assigned before.
+// ErrUnknownUser is returned for a user that does not exist and ErrNotScorekeeper for a viewer.
+func (x *Authenticator) Assign(matchId uint32, names []string) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	for _, name := range names {
+		user, ok := x.users[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownUser, name)
+		}
+		if !user.Role.Allows(RoleScorekeeper) {
+			return fmt.Errorf("%w: %q", ErrNotScorekeeper, name)
+		}
+	}
+
+	scorekeepers := slices.Clone(names)
+	slices.Sort(scorekeepers)
+	scorekeepers = slices.Compact(scorekeepers)
+	if len(scorekeepers) == 0 {
+		delete(x.assignments, matchId)
+		return nil
+	}
+	x.assignments[matchId] = scorekeepers
+	return nil
+}
+
+// AssignScorekeeper adds the user to the scorekeepers of the match with the provided id,
+// e.g. when the user starts the match. Users that are not scorekeepers are not assigned.
+func (x *Authenticator) AssignScorekeeper(matchId uint32, user User) {
+	if user.Role != RoleScorekeeper {
+		return
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	scorekeepers := x.assignments[matchId]
+	if index, found := slices.BinarySearch(scorekeepers, user.Name); !found {
+		x.assignments[matchId] = slices.Insert(scorekeepers, index, user.Name)
+	}
+}
+
+// Scorekeepers returns the names of the users assigned to the match with the provided id, sorted.
+func (x *Authenticator) Scorekeepers(matchId uint32) []string {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return slices.Clone(x.assignments[matchId])
+}
+
+// MayUpdate reports whether the user may change the match with the provided id:
+// admins may change every match and scorekeepers the matches assigned to them.
+func (x *Authenticator) MayUpdate(user User, matchId uint32) bool {
+	if user.Role.Allows(RoleAdmin) {
+		return true
+	}
+	if !user.Role.Allows(RoleScorekeeper) {
+		return false
+	}
+
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return slices.Contains(x.assignments[matchId], user.Name)
+}
+
+// userKey is the context key of the signed in user of a request.
+type userKey struct{}
+
+// WithUser returns a copy of the context carrying the signed in user.
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the signed in user carried by the context and reports whether there is one.
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
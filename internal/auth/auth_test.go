@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testUsers returns an admin, two scorekeepers and a viewer, all with the password "secret",
+// and a scorekeeper signing in with the API token "bot-token" only.
+func testUsers(t *testing.T) []User {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []User{
+		{Name: "alice", Role: RoleAdmin, PasswordHash: string(hash)},
+		{Name: "bob", Role: RoleScorekeeper, PasswordHash: string(hash)},
+		{Name: "carol", Role: RoleScorekeeper, PasswordHash: string(hash)},
+		{Name: "dave", Role: RoleViewer, PasswordHash: string(hash)},
+		{Name: "bot", Role: RoleScorekeeper, TokenHashes: []string{HashToken("bot-token")}},
+	}
+}
+
+func newTestAuthenticator(t *testing.T) *Authenticator {
+	x, err := New(Config{Users: testUsers(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestRole_Allows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{role: RoleAdmin, required: RoleScorekeeper, want: true},
+		{role: RoleScorekeeper, required: RoleScorekeeper, want: true},
+		{role: RoleScorekeeper, required: RoleAdmin, want: false},
+		{role: RoleViewer, required: RoleViewer, want: true},
+		{role: RoleViewer, required: RoleScorekeeper, want: false},
+		{role: "owner", required: RoleViewer, want: false},
+		{role: "", required: RoleViewer, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+string(tt.required), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.role.Allows(tt.required))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   []User
+		wantErr error
+	}{
+		{name: "Valid", users: []User{{Name: "bob", Role: RoleViewer, PasswordHash: "hash"}}},
+		{name: "Without name", users: []User{{Role: RoleViewer, PasswordHash: "hash"}}, wantErr: ErrInvalidUser},
+		{name: "Unknown role", users: []User{{Name: "bob", Role: "owner", PasswordHash: "hash"}}, wantErr: ErrInvalidUser},
+		{name: "Without credentials", users: []User{{Name: "bob", Role: RoleViewer}}, wantErr: ErrInvalidUser},
+		{
+			name: "Same name",
+			users: []User{
+				{Name: "bob", Role: RoleViewer, PasswordHash: "hash"},
+				{Name: "bob", Role: RoleAdmin, PasswordHash: "hash"},
+			},
+			wantErr: ErrDuplicateUser,
+		},
+		{
+			name: "Same token",
+			users: []User{
+				{Name: "bob", Role: RoleViewer, TokenHashes: []string{HashToken("token")}},
+				{Name: "carol", Role: RoleViewer, TokenHashes: []string{HashToken("token")}},
+			},
+			wantErr: ErrInvalidUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Users: tt.users})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthenticator_Login(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     string
+		password string
+		wantErr  error
+	}{
+		{name: "Valid", user: "bob", password: "secret"},
+		{name: "Wrong password", user: "bob", password: "guess", wantErr: ErrInvalidCredentials},
+		{name: "Unknown user", user: "eve", password: "secret", wantErr: ErrInvalidCredentials},
+		{name: "Token only", user: "bot", password: "", wantErr: ErrInvalidCredentials},
+	}
+
+	x := newTestAuthenticator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := x.Login(tt.user, tt.password)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, tt.user, session.User)
+			assert.NotEmpty(t, session.Token)
+
+			user, ok := x.Authenticate(session.Token)
+			assert.True(t, ok)
+			assert.Equal(t, tt.user, user.Name)
+		})
+	}
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	x := newTestAuthenticator(t)
+	now := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	x.now = func() time.Time { return now }
+
+	user, ok := x.Authenticate("bot-token")
+	assert.True(t, ok)
+	assert.Equal(t, "bot", user.Name)
+	_, ok = x.Authenticate("wrong-token")
+	assert.False(t, ok)
+	_, ok = x.Authenticate("")
+	assert.False(t, ok)
+
+	session, err := x.Login("bob", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(SessionTTL), session.ExpiresAt)
+
+	// The session is presented as a bearer token or in the session cookie.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+session.Token)
+	user, ok = x.AuthenticateRequest(req)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", user.Name)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session.Token})
+	user, ok = x.AuthenticateRequest(req)
+	assert.True(t, ok)
+	assert.Equal(t, "bob", user.Name)
+	req.Header.Set("Authorization", "Basic "+session.Token)
+	_, ok = x.AuthenticateRequest(req)
+	assert.False(t, ok)
+
+	now = now.Add(SessionTTL)
+	_, ok = x.Authenticate(session.Token)
+	assert.False(t, ok)
+
+	session, err = x.Login("bob", "secret")
+	assert.NoError(t, err)
+	x.Logout(session.Token)
+	_, ok = x.Authenticate(session.Token)
+	assert.False(t, ok)
+}
+
+func TestAuthenticator_Assign(t *testing.T) {
+	x := newTestAuthenticator(t)
+	users := make(map[string]User)
+	for _, user := range testUsers(t) {
+		users[user.Name] = user
+	}
+
+	assert.False(t, x.MayUpdate(users["bob"], 1))
+	assert.True(t, x.MayUpdate(users["alice"], 1))
+
+	x.AssignScorekeeper(1, users["bob"])
+	x.AssignScorekeeper(1, users["bob"])
+	x.AssignScorekeeper(1, users["alice"])
+	assert.Equal(t, []string{"bob"}, x.Scorekeepers(1))
+	assert.True(t, x.MayUpdate(users["bob"], 1))
+	assert.False(t, x.MayUpdate(users["bob"], 2))
+	assert.False(t, x.MayUpdate(users["carol"], 1))
+
+	assert.NoError(t, x.Assign(1, []string{"carol", "bot", "carol"}))
+	assert.Equal(t, []string{"bot", "carol"}, x.Scorekeepers(1))
+	assert.False(t, x.MayUpdate(users["bob"], 1))
+	assert.True(t, x.MayUpdate(users["carol"], 1))
+
+	assert.ErrorIs(t, x.Assign(1, []string{"eve"}), ErrUnknownUser)
+	assert.ErrorIs(t, x.Assign(1, []string{"dave"}), ErrNotScorekeeper)
+	assert.False(t, x.MayUpdate(users["dave"], 1))
+	assert.Equal(t, []string{"bot", "carol"}, x.Scorekeepers(1))
+
+	assert.NoError(t, x.Assign(1, nil))
+	assert.Empty(t, x.Scorekeepers(1))
+}
+
+func TestLoad(t *testing.T) {
+	hash := testUsers(t)[0].PasswordHash
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{
+			name: "YAML",
+			file: "users.yaml",
+			data: "require_login: true\nusers:\n  - name: alice\n    role: admin\n    password_hash: " + hash + "\n",
+		},
+		{
+			name: "JSON",
+			file: "users.json",
+			data: `{"require_login": true, "users": [{"name": "alice", "role": "admin", "password_hash": "` + hash + `"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.data), 0o600))
+
+			x, err := Load(path)
+			assert.NoError(t, err)
+			assert.True(t, x.RequireLogin())
+			_, err = x.Login("alice", "secret")
+			assert.NoError(t, err)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "errors"
+
+var (
+	ErrInvalidCredentials = errors.New("invalid user name or password")
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrDuplicateUser      = errors.New("user already exists")
+	ErrUnknownUser        = errors.New("unknown user")
+	ErrNotScorekeeper     = errors.New("user cannot keep scores")
+)
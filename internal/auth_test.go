@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newTestAuthApp returns an initialized App with an admin, two scorekeepers and a viewer signing in
+// with the API tokens named after them, and a scorekeeper logging in with the password "secret".
+func newTestAuthApp(t *testing.T, requireLogin bool) *App {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := auth.New(auth.Config{
+		RequireLogin: requireLogin,
+		Users: []auth.User{
+			{Name: "alice", Role: auth.RoleAdmin, TokenHashes: []string{auth.HashToken("alice-token")}},
+			{Name: "bob", Role: auth.RoleScorekeeper, TokenHashes: []string{auth.HashToken("bob-token")}},
+			{Name: "carol", Role: auth.RoleScorekeeper, TokenHashes: []string{auth.HashToken("carol-token")}},
+			{Name: "dave", Role: auth.RoleViewer, TokenHashes: []string{auth.HashToken("dave-token")}},
+			{Name: "erin", Role: auth.RoleScorekeeper, PasswordHash: string(hash)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := NewApp(NewScoreBase(), NewScoreBoard(), WithAuth(authenticator))
+	app.InitRoutes()
+	return app
+}
+
+// doAuthRequest sends a request with the provided bearer token, if any, to the App and returns the recorded response.
+func doAuthRequest(app *App, token, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestApi_Auth(t *testing.T) {
+	app := newTestAuthApp(t, false)
+	rec := doAuthRequest(app, "bob-token", http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{name: "Anonymous read", method: http.MethodGet, target: "/api/v1/matches", wantCode: http.StatusOK},
+		{name: "Anonymous start", method: http.MethodPost, target: "/api/v1/matches", body: `{"home_team": "USA", "away_team": "Italy"}`, wantCode: http.StatusUnauthorized},
+		{name: "Unknown token", token: "eve-token", method: http.MethodPost, target: "/api/v1/matches", body: `{"home_team": "USA", "away_team": "Italy"}`, wantCode: http.StatusUnauthorized},
+		{name: "Viewer start", token: "dave-token", method: http.MethodPost, target: "/api/v1/matches", body: `{"home_team": "USA", "away_team": "Italy"}`, wantCode: http.StatusForbidden},
+		{name: "Viewer update", token: "dave-token", method: http.MethodPatch, target: "/api/v1/matches/1", body: `{"home_score": 1, "away_score": 0}`, wantCode: http.StatusForbidden},
+		{name: "Unassigned scorekeeper update", token: "carol-token", method: http.MethodPatch, target: "/api/v1/matches/1", body: `{"home_score": 1, "away_score": 0}`, wantCode: http.StatusForbidden},
+		{name: "Unassigned scorekeeper goal", token: "carol-token", method: http.MethodPost, target: "/api/v1/matches/1/goals", body: `{"minute": 10, "team": "Spain", "player": "Morata"}`, wantCode: http.StatusForbidden},
+		{name: "Unassigned scorekeeper transition", token: "carol-token", method: http.MethodPost, target: "/api/v1/matches/1/half_time", wantCode: http.StatusForbidden},
+		{name: "Assigned scorekeeper update", token: "bob-token", method: http.MethodPatch, target: "/api/v1/matches/1", body: `{"home_score": 1, "away_score": 0}`, wantCode: http.StatusOK},
+		{name: "Admin update", token: "alice-token", method: http.MethodPatch, target: "/api/v1/matches/1", body: `{"home_score": 2, "away_score": 0}`, wantCode: http.StatusOK},
+		{name: "Invalid id", token: "carol-token", method: http.MethodPatch, target: "/api/v1/matches/abc", body: `{"home_score": 1, "away_score": 0}`, wantCode: http.StatusBadRequest},
+		{name: "Scorekeeper assigns", token: "bob-token", method: http.MethodPut, target: "/api/v1/matches/1/scorekeepers", body: `{"scorekeepers": ["bob", "carol"]}`, wantCode: http.StatusForbidden},
+		{name: "Assign viewer", token: "alice-token", method: http.MethodPut, target: "/api/v1/matches/1/scorekeepers", body: `{"scorekeepers": ["dave"]}`, wantCode: http.StatusBadRequest},
+		{name: "Assign to missing match", token: "alice-token", method: http.MethodPut, target: "/api/v1/matches/99/scorekeepers", body: `{"scorekeepers": ["carol"]}`, wantCode: http.StatusNotFound},
+		{name: "Admin assigns", token: "alice-token", method: http.MethodPut, target: "/api/v1/matches/1/scorekeepers", body: `{"scorekeepers": ["carol"]}`, wantCode: http.StatusOK},
+		{name: "Newly assigned scorekeeper update", token: "carol-token", method: http.MethodPatch, target: "/api/v1/matches/1", body: `{"home_score": 3, "away_score": 0}`, wantCode: http.StatusOK},
+		{name: "Previously assigned scorekeeper finish", token: "bob-token", method: http.MethodPost, target: "/api/v1/matches/1/finish", wantCode: http.StatusForbidden},
+		{name: "Assigned scorekeeper finish", token: "carol-token", method: http.MethodPost, target: "/api/v1/matches/1/finish", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAuthRequest(app, tt.token, tt.method, tt.target, tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+
+	rec = doAuthRequest(app, "", http.MethodGet, "/api/v1/matches/1/scorekeepers", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "{\"scorekeepers\":[\"carol\"]}\n", rec.Body.String())
+
+	// Score changes are recorded as made by the signed in user, whatever actor the request names.
+	rec = doAuthRequest(app, "", http.MethodGet, "/api/v1/matches/1/history", "")
+	var history []AuditEntry
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&history))
+	assert.Equal(t, 3, len(history))
+	assert.Equal(t, []string{"bob", "alice", "carol"}, []string{history[0].Actor, history[1].Actor, history[2].Actor})
+}
+
+func TestApi_Auth_Login(t *testing.T) {
+	app := newTestAuthApp(t, false)
+
+	rec := doAuthRequest(app, "", http.MethodPost, "/api/v1/login", `{"name": "erin", "password": "guess"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doAuthRequest(app, "", http.MethodPost, "/api/v1/login", `{"name": "erin", "password": "secret"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var session auth.Session
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&session))
+	assert.Equal(t, auth.RoleScorekeeper, session.Role)
+
+	rec = doAuthRequest(app, session.Token, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = doAuthRequest(app, session.Token, http.MethodPost, "/api/v1/logout", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doAuthRequest(app, session.Token, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 1, "away_score": 0}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestApp_Auth_Login(t *testing.T) {
+	app := newTestAuthApp(t, false)
+
+	post := func(target string, values url.Values, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		app.Server.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/start_game", url.Values{"country1": {"Spain"}, "country2": {"Brazil"}})
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/login", rec.Header().Get("Location"))
+
+	rec = doAuthRequest(app, "", http.MethodGet, "/login", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<form method="post" action="/login">`)
+
+	rec = post("/login", url.Values{"name": {"erin"}, "password": {"guess"}})
+	assert.Equal(t, "/login?failed", rec.Header().Get("Location"))
+	rec = doAuthRequest(app, "", http.MethodGet, "/login?failed", "")
+	assert.Contains(t, rec.Body.String(), "Invalid user name or password")
+
+	rec = post("/login", url.Values{"name": {"erin"}, "password": {"secret"}})
+	assert.Equal(t, "/", rec.Header().Get("Location"))
+	cookies := rec.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	assert.True(t, cookies[0].HttpOnly)
+	session := cookies[0]
+
+	rec = post("/start_game", url.Values{"country1": {"Spain"}, "country2": {"Brazil"}}, session)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/", rec.Header().Get("Location"))
+	rec = post("/update_score", url.Values{"matchIndex": {"1"}, "score1": {"1"}, "score2": {"0"}}, session)
+	assert.Equal(t, "/", rec.Header().Get("Location"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(session)
+	rec = httptest.NewRecorder()
+	app.Server.Handler.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), "Signed in as erin")
+	assert.Contains(t, rec.Body.String(), "Spain - Brazil | 1 : 0 | live")
+
+	rec = post("/logout", nil, session)
+	assert.Equal(t, "/", rec.Header().Get("Location"))
+	rec = post("/update_score", url.Values{"matchIndex": {"1"}, "score1": {"2"}, "score2": {"0"}}, session)
+	assert.Equal(t, "/login", rec.Header().Get("Location"))
+}
+
+func TestApp_Auth_RequireLogin(t *testing.T) {
+	app := newTestAuthApp(t, true)
+
+	tests := []struct {
+		name     string
+		token    string
+		target   string
+		wantCode int
+	}{
+		{name: "Anonymous page", target: "/", wantCode: http.StatusSeeOther},
+		{name: "Anonymous API", target: "/api/v1/matches", wantCode: http.StatusUnauthorized},
+		{name: "Login page", target: "/login", wantCode: http.StatusOK},
+		{name: "Viewer page", token: "dave-token", target: "/", wantCode: http.StatusOK},
+		{name: "Viewer API", token: "dave-token", target: "/api/v1/matches", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doAuthRequest(app, tt.token, http.MethodGet, tt.target, "")
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"sync"
+	"time"
+)
+
+// EventType defines the kind of change that happened to a game.
+type EventType string
+
+const (
+	// EventMatchStarted is published when a game is started on the board.
+	EventMatchStarted EventType = "match_started"
+	// EventMatchScheduled is published when a game is scheduled on the board.
+	EventMatchScheduled EventType = "match_scheduled"
+	// EventStatusChanged is published when a game on the board is moved to another status of its lifecycle.
+	EventStatusChanged EventType = "status_changed"
+	// EventScoreUpdated is published when the score of a game on the board is updated.
+	EventScoreUpdated EventType = "score_updated"
+	// EventGoalScored is published when a goal of a game on the board is recorded, the goal is in the timeline of the game.
+	EventGoalScored EventType = "goal_scored"
+	// EventMatchFinished is published when a game is removed from the board.
+	EventMatchFinished EventType = "match_finished"
+	// EventMatchRecorded is published when a finished game is inserted into the score base.
+	EventMatchRecorded EventType = "match_recorded"
+)
+
+const (
+	// DefaultEventHistory is the default number of events kept by the broker for replaying to reconnecting clients.
+	DefaultEventHistory = 1024
+	// subscriberBufferSize is the number of events buffered for every subscriber before it is considered too slow.
+	subscriberBufferSize = 64
+)
+
+// Event represents a single change of a game. The game is copied at the moment the event is published,
+// so later changes of the game do not affect already published events.
+type Event struct {
+	Id   uint64      `json:"id"`
+	Type EventType   `json:"type"`
+	At   time.Time   `json:"at"`
+	Game models.Game `json:"game"`
+}
+
+// EventBroker fans out published events to subscribers and keeps the most recent events in a ring buffer,
+// so subscribers that reconnect with the id of the last event they have seen can replay the missed ones.
+type EventBroker struct {
+	lock        sync.Mutex
+	history     []Event
+	head        int
+	size        int
+	lastId      uint64
+	subscribers map[*Subscription]struct{}
+}
+
+// NewEventBroker returns a new instance of EventBroker keeping up to history events for replay.
+func NewEventBroker(history int) *EventBroker {
+	if history < 1 {
+		history = 1
+	}
+	return &EventBroker{
+		history:     make([]Event, history),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription represents a subscriber of the EventBroker.
+// Events are delivered on C, which is closed when the subscription is closed or
+// when the subscriber falls too far behind; in the latter case it should subscribe again
+// with the id of the last received event.
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	broker *EventBroker
+}
+
+// Publish stores a new event of the provided type for the game in the history and delivers it to all subscribers.
+// Subscribers whose buffers are full are dropped instead of blocking the publisher.
+func (x *EventBroker) Publish(eventType EventType, game *models.Game) Event {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.lastId++
+	event := Event{
+		Id:   x.lastId,
+		Type: eventType,
+		At:   time.Now(),
+		Game: *game,
+	}
+
+	x.history[(x.head+x.size)%len(x.history)] = event
+	if x.size < len(x.history) {
+		x.size++
+	} else {
+		x.head = (x.head + 1) % len(x.history)
+	}
+
+	for sub := range x.subscribers {
+		select {
+		case sub.c <- event:
+		default:
+			x.drop(sub)
+		}
+	}
+
+	return event
+}
+
+// Subscribe registers a new subscriber and returns it together with the events published after lastId
+// that are still kept in the history. A lastId of zero means that nothing has to be replayed.
+func (x *EventBroker) Subscribe(lastId uint64) (*Subscription, []Event) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	var missed []Event
+	if lastId > 0 {
+		for i := 0; i < x.size; i++ {
+			event := x.history[(x.head+i)%len(x.history)]
+			if event.Id > lastId {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	c := make(chan Event, subscriberBufferSize)
+	sub := &Subscription{C: c, c: c, broker: x}
+	x.subscribers[sub] = struct{}{}
+
+	return sub, missed
+}
+
+// Close unsubscribes the subscription from the broker and closes its channel.
+// It is safe to call Close on an already dropped subscription.
+func (x *Subscription) Close() {
+	x.broker.lock.Lock()
+	defer x.broker.lock.Unlock()
+
+	x.broker.drop(x)
+}
+
+// drop removes the subscription from the broker and closes its channel. The lock must be held by the caller.
+func (x *EventBroker) drop(sub *Subscription) {
+	if _, ok := x.subscribers[sub]; !ok {
+		return
+	}
+	delete(x.subscribers, sub)
+	close(sub.c)
+}
@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"bufio"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEventBroker_Subscribe_Replay(t *testing.T) {
+	tests := []struct {
+		name    string
+		lastId  uint64
+		wantIds []uint64
+	}{
+		{
+			name:    "Nothing to replay",
+			lastId:  0,
+			wantIds: nil,
+		},
+		{
+			name:    "Replay missed events",
+			lastId:  4,
+			wantIds: []uint64{5, 6},
+		},
+		{
+			name:    "Replay is limited by history",
+			lastId:  1,
+			wantIds: []uint64{3, 4, 5, 6},
+		},
+		{
+			name:    "Up to date",
+			lastId:  6,
+			wantIds: nil,
+		},
+	}
+
+	broker := NewEventBroker(4)
+	game := &models.Game{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil}
+	for i := 0; i < 6; i++ {
+		broker.Publish(EventScoreUpdated, game)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed := broker.Subscribe(tt.lastId)
+			defer sub.Close()
+			var gotIds []uint64
+			for _, event := range missed {
+				gotIds = append(gotIds, event.Id)
+			}
+			assert.Equal(t, tt.wantIds, gotIds)
+		})
+	}
+}
+
+func TestEventBroker_Publish_SlowSubscriber(t *testing.T) {
+	broker := NewEventBroker(DefaultEventHistory)
+	sub, _ := broker.Subscribe(0)
+	game := &models.Game{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil}
+
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		broker.Publish(EventScoreUpdated, game)
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received)
+	sub.Close()
+}
+
+func TestObservedBoard_PublishesEvents(t *testing.T) {
+	broker := NewEventBroker(DefaultEventHistory)
+	board := NewObservedBoard(NewScoreBoard(), broker)
+	store := NewObservedScoreBase(NewScoreBase(), broker)
+	sub, _ := broker.Subscribe(0)
+	defer sub.Close()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.StartGame("Norway", "Brazil")
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+	_, err = board.UpdateGame(game.Id, 1, 0)
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(99, 1, 0)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	game, err = board.RemoveGame(game.Id)
+	assert.NoError(t, err)
+	store.Insert(game)
+
+	wantTypes := []EventType{EventMatchStarted, EventScoreUpdated, EventMatchFinished, EventMatchRecorded}
+	for i, want := range wantTypes {
+		event := <-sub.C
+		assert.Equal(t, uint64(i+1), event.Id)
+		assert.Equal(t, want, event.Type)
+	}
+
+	replay, missed := broker.Subscribe(1)
+	replay.Close()
+	assert.Equal(t, 3, len(missed))
+	assert.Equal(t, uint(1), missed[0].Game.HomeScore)
+}
+
+func TestApp_Events_ResumeFromLastEventId(t *testing.T) {
+	broker := NewEventBroker(DefaultEventHistory)
+	app := NewApp(NewScoreBase(), NewObservedBoard(NewScoreBoard(), broker), WithEvents(broker))
+	app.InitRoutes()
+	server := httptest.NewServer(app.Server.Handler)
+	defer server.Close()
+
+	game, err := app.board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = app.board.UpdateGame(game.Id, 1, 0)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/events", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	_, err = app.board.UpdateGame(game.Id, 2, 0)
+	assert.NoError(t, err)
+
+	reader := bufio.NewReader(resp.Body)
+	var ids, types []string
+	for len(types) < 2 {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+		if eventType, ok := strings.CutPrefix(line, "event: "); ok {
+			types = append(types, eventType)
+		}
+	}
+	assert.Equal(t, []string{"2", "3"}, ids)
+	assert.Equal(t, []string{string(EventScoreUpdated), string(EventScoreUpdated)}, types)
+}
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// scoreBoardJournalName is the name of the journal files of FileScoreBoard in the data directory.
+const scoreBoardJournalName = "scoreboard"
+
+const (
+	// boardOpStart is the journal operation of starting a game.
+	boardOpStart = "start"
+	// boardOpUpdate is the journal operation of updating a game.
+	boardOpUpdate = "update"
+	// boardOpRemove is the journal operation of removing a game.
+	boardOpRemove = "remove"
+	// boardOpGoal is the journal operation of recording a goal of a game.
+	boardOpGoal = "goal"
+	// boardOpTransition is the journal operation of moving a game through its lifecycle.
+	boardOpTransition = "transition"
+	// boardOpShootout is the journal operation of updating the penalty shootout score of a game.
+	boardOpShootout = "shootout"
+)
+
+// boardRecord defines a single journaled operation of FileScoreBoard together with the state of the game after it.
+type boardRecord struct {
+	Op   string      `json:"op"`
+	Game models.Game `json:"game"`
+}
+
+// boardSnapshot defines the snapshot of FileScoreBoard. NextId keeps the id counter,
+// because the games holding the highest ids may have been removed already.
+type boardSnapshot struct {
+	NextId uint32         `json:"next_id"`
+	Games  []*models.Game `json:"games"`
+}
+
+// FileScoreBoard is a crash-safe implementation of GameBoard.
+// Every start, update, goal, transition, shootout update and remove is journaled to an fsync'd append-only log before it is visible in the in-memory ScoreBoard,
+// the log is replayed on startup to restore both the games and the id counter, so ids are never reused across restarts.
+type FileScoreBoard struct {
+	board   *ScoreBoard
+	journal *journal
+	lock    sync.Mutex
+	logger  *log.Logger
+}
+
+// NewFileScoreBoard opens the scoreboard stored in dir, restoring all active games and the id counter.
+func NewFileScoreBoard(dir string) (*FileScoreBoard, error) {
+	board := NewScoreBoard()
+
+	var snapshot boardSnapshot
+	var records []boardRecord
+	j, err := openJournal(dir, scoreBoardJournalName, &snapshot, func(data json.RawMessage) error {
+		var record boardRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	board.reserveIds(snapshot.NextId)
+	for _, game := range snapshot.Games {
+		board.restore(game)
+	}
+	for _, record := range records {
+		game := record.Game
+		switch record.Op {
+		case boardOpStart, boardOpUpdate, boardOpGoal, boardOpTransition, boardOpShootout:
+			board.restore(&game)
+		case boardOpRemove:
+			_, _ = board.RemoveGame(game.Id)
+			board.reserveIds(game.Id)
+		default:
+			_ = j.close()
+			return nil, fmt.Errorf("unknown scoreboard journal operation %q", record.Op)
+		}
+	}
+
+	return &FileScoreBoard{
+		board:   board,
+		journal: j,
+		logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}, nil
+}
+
+// StartGame journals the new live game and stores it in the in-memory scoreboard.
+// If the game cannot be journaled, it is never visible and the error is returned.
+func (x *FileScoreBoard) StartGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusLive)
+}
+
+// ScheduleGame journals the new scheduled game and stores it in the in-memory scoreboard the same way as StartGame.
+func (x *FileScoreBoard) ScheduleGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusScheduled)
+}
+
+// addGame builds the game with the provided teams and status, reserves its teams and journals it,
+// and only then stores it in the in-memory scoreboard. The teams are released again if journaling fails.
+func (x *FileScoreBoard) addGame(homeTeam, awayTeam string, status models.Status) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	game, err := x.board.newGame(homeTeam, awayTeam, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.board.claimTeams(game.Id, game.HomeTeam, game.AwayTeam); err != nil {
+		return nil, err
+	}
+	if err := x.journal.append(boardRecord{Op: boardOpStart, Game: *game}); err != nil {
+		x.board.releaseTeams(game)
+		return nil, err
+	}
+	x.board.Games.Store(game.Id, game)
+	return game, nil
+}
+
+// RemoveGame journals the removal of the game and removes it from the in-memory scoreboard.
+func (x *FileScoreBoard) RemoveGame(id uint32) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if _, ok := x.board.Games.Load(id); !ok {
+		return nil, models.ErrGameNotFound
+	}
+	if err := x.journal.append(boardRecord{Op: boardOpRemove, Game: models.Game{Id: id}}); err != nil {
+		return nil, err
+	}
+	return x.board.RemoveGame(id)
+}
+
+// RestoreGame journals the removed game and puts it back on the in-memory scoreboard the same way as ScoreBoard.RestoreGame.
+func (x *FileScoreBoard) RestoreGame(game *models.Game) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if err := x.board.claimTeams(game.Id, game.HomeTeam, game.AwayTeam); err != nil {
+		return err
+	}
+	if err := x.journal.append(boardRecord{Op: boardOpStart, Game: *game}); err != nil {
+		x.board.releaseTeams(game)
+		return err
+	}
+	x.board.Games.Store(game.Id, game)
+	return nil
+}
+
+// UpdateGame journals the game with the new score and swaps it into the in-memory scoreboard.
+func (x *FileScoreBoard) UpdateGame(id uint32, homeScore, awayScore uint) (*models.Game, error) {
+	return x.changeGame(boardOpUpdate, id, func(game *models.Game) error {
+		return game.SetScore(homeScore, awayScore)
+	})
+}
+
+// CompareAndSwapScore journals the game with the new score, if it still has the old score, and swaps it into the in-memory scoreboard.
+func (x *FileScoreBoard) CompareAndSwapScore(id uint32, old models.Score, homeScore, awayScore uint) (*models.Game, error) {
+	return x.changeGame(boardOpUpdate, id, func(game *models.Game) error {
+		return setScoreFrom(game, old, homeScore, awayScore)
+	})
+}
+
+// RecordGoal journals the game with the goal added and records the goal on the in-memory scoreboard.
+func (x *FileScoreBoard) RecordGoal(id uint32, goal models.Goal) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	current, ok := x.board.Games.Load(id)
+	if !ok {
+		return nil, models.ErrGameNotFound
+	}
+	updated := *current.(*models.Game)
+	if err := updated.AddGoal(goal); err != nil {
+		return nil, err
+	}
+	if err := x.journal.append(boardRecord{Op: boardOpGoal, Game: updated}); err != nil {
+		return nil, err
+	}
+	return x.board.RecordGoal(id, goal)
+}
+
+// TransitionGame journals the game moved through the transition and applies the transition on the in-memory scoreboard.
+func (x *FileScoreBoard) TransitionGame(id uint32, transition models.Transition) (*models.Game, error) {
+	return x.changeGame(boardOpTransition, id, func(game *models.Game) error {
+		return game.Transition(transition)
+	})
+}
+
+// KickoffGame journals the scheduled game kicked off at the provided time and applies the kickoff on the in-memory scoreboard.
+func (x *FileScoreBoard) KickoffGame(id uint32, at time.Time) (*models.Game, error) {
+	return x.changeGame(boardOpTransition, id, func(game *models.Game) error {
+		return game.Kickoff(at)
+	})
+}
+
+// UpdateShootout journals the new penalty shootout score of the game and sets it on the in-memory scoreboard.
+func (x *FileScoreBoard) UpdateShootout(id uint32, homePenalties, awayPenalties uint) (*models.Game, error) {
+	return x.changeGame(boardOpShootout, id, func(game *models.Game) error {
+		return game.SetPenaltyScore(homePenalties, awayPenalties)
+	})
+}
+
+// changeGame journals a copy of the game with the change applied as the provided operation
+// and swaps the copy into the in-memory scoreboard.
+func (x *FileScoreBoard) changeGame(op string, id uint32, change func(game *models.Game) error) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	current, ok := x.board.Games.Load(id)
+	if !ok {
+		return nil, models.ErrGameNotFound
+	}
+	updated := *current.(*models.Game)
+	if err := change(&updated); err != nil {
+		return nil, err
+	}
+	if err := x.journal.append(boardRecord{Op: op, Game: updated}); err != nil {
+		return nil, err
+	}
+	x.board.Games.Store(id, &updated)
+	return &updated, nil
+}
+
+// GetGame returns the active game with the provided id from the in-memory scoreboard.
+func (x *FileScoreBoard) GetGame(id uint32) (*models.Game, error) {
+	return x.board.GetGame(id)
+}
+
+// GetGames returns all active games from the in-memory scoreboard.
+func (x *FileScoreBoard) GetGames() []*models.Game {
+	return x.board.GetGames()
+}
+
+// Compact writes all active games and the id counter into the snapshot file and empties the log.
+// Nothing is done if no operation was journaled since the last compaction.
+func (x *FileScoreBoard) Compact() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if x.journal.pending() == 0 {
+		return nil
+	}
+	return x.journal.compact(boardSnapshot{
+		NextId: atomic.LoadUint32(&x.board.nextId),
+		Games:  nonNilGames(x.board.GetGames()),
+	})
+}
+
+// RunCompaction compacts the log every interval until the context is done.
+func (x *FileScoreBoard) RunCompaction(ctx context.Context, interval time.Duration) {
+	runCompaction(ctx, interval, x.Compact, x.logger)
+}
+
+// Close closes the log file. The scoreboard must not be used afterwards.
+func (x *FileScoreBoard) Close() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.journal.close()
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFileScoreBoard_Reopen(t *testing.T) {
+	tests := []struct {
+		name    string
+		compact bool
+	}{
+		{
+			name:    "Replaying log",
+			compact: false,
+		},
+		{
+			name:    "Reading snapshot",
+			compact: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			board, err := NewFileScoreBoard(dir)
+			assert.NoError(t, err)
+
+			spain, err := board.StartGame("Spain", "Brazil")
+			assert.NoError(t, err)
+			usa, err := board.StartGame("USA", "Italy")
+			assert.NoError(t, err)
+			last, err := board.StartGame("Germany", "France")
+			assert.NoError(t, err)
+			_, err = board.UpdateGame(spain.Id, 3, 1)
+			assert.NoError(t, err)
+			_, err = board.RemoveGame(last.Id)
+			assert.NoError(t, err)
+			if tt.compact {
+				assert.NoError(t, board.Compact())
+			}
+			_, err = board.UpdateGame(usa.Id, 0, 2)
+			assert.NoError(t, err)
+			assert.NoError(t, board.Close())
+
+			reopened, err := NewFileScoreBoard(dir)
+			assert.NoError(t, err)
+			defer reopened.Close()
+
+			games := reopened.GetGames()
+			assert.Equal(t, 2, len(games))
+			for _, game := range games {
+				switch game.Id {
+				case spain.Id:
+					assert.Equal(t, uint(3), game.HomeScore)
+					assert.Equal(t, uint(1), game.AwayScore)
+				case usa.Id:
+					assert.Equal(t, uint(0), game.HomeScore)
+					assert.Equal(t, uint(2), game.AwayScore)
+				default:
+					t.Errorf("unexpected game restored: %v", game.Id)
+				}
+			}
+
+			next, err := reopened.StartGame("Japan", "China")
+			assert.NoError(t, err)
+			assert.Equal(t, last.Id+1, next.Id)
+
+			// Teams of restored games are still playing, the teams of the removed game are free again.
+			_, err = reopened.StartGame("Brazil", "Germany")
+			assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+			_, err = reopened.StartGame("France", "Germany")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFileScoreBoard_WrongId(t *testing.T) {
+	board, err := NewFileScoreBoard(t.TempDir())
+	assert.NoError(t, err)
+	defer board.Close()
+
+	_, err = board.UpdateGame(99, 1, 1)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	_, err = board.RemoveGame(99)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	assert.Equal(t, 0, board.journal.pending())
+}
+
+func TestFileScoreBoard_StartGame_JournalFails(t *testing.T) {
+	board, err := NewFileScoreBoard(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, board.journal.close())
+
+	// A game that cannot be journaled is never visible and does not keep its teams.
+	_, err = board.StartGame("Spain", "Brazil")
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(board.GetGames()))
+	_, err = board.board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+}
+
+func TestFileScoreBoard_RecordGoal_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	board, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Brazil, Player: "Neymar", Type: models.GoalPenalty})
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Spain, Player: "Morata", Type: models.GoalOwn})
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Italy, Player: "Rossi", Type: models.GoalRegular})
+	assert.ErrorIs(t, err, models.ErrInvalidGoal)
+	want := board.GetGames()
+	assert.NoError(t, board.Close())
+
+	reopened, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	games := reopened.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, want[0].Goals, games[0].Goals)
+	assert.Equal(t, uint(1), games[0].HomeScore)
+	assert.Equal(t, uint(1), games[0].AwayScore)
+}
+
+func TestFileScoreBoard_RestoreGame_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	board, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	game, err = board.UpdateGame(game.Id, 2, 1)
+	assert.NoError(t, err)
+	removed, err := board.RemoveGame(game.Id)
+	assert.NoError(t, err)
+	_, err = board.StartGame("Brazil", "Italy")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, board.RestoreGame(removed), models.ErrTeamAlreadyPlaying)
+	_, err = board.StartGame("Spain", "Japan")
+	assert.NoError(t, err, "a failed restore must not keep the teams")
+	_, err = board.RemoveGame(3)
+	assert.NoError(t, err)
+	_, err = board.RemoveGame(2)
+	assert.NoError(t, err)
+	assert.NoError(t, board.RestoreGame(removed))
+	assert.NoError(t, board.Close())
+
+	reopened, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	games := reopened.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, game.Id, games[0].Id)
+	assert.Equal(t, uint(2), games[0].HomeScore)
+	started, err := reopened.StartGame("USA", "Egypt")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), started.Id)
+}
+
+func TestFileScoreBoard_TransitionGame_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	board, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+
+	game, err := board.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 0)
+	assert.ErrorIs(t, err, models.ErrScoreUpdateNotAllowed)
+	_, err = board.TransitionGame(game.Id, models.TransitionKickoff)
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(game.Id, models.TransitionHalfTime)
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(game.Id, models.TransitionShootout)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	assert.NoError(t, board.Close())
+
+	reopened, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, models.StatusHalfTime, reopened.GetGames()[0].Status)
+}
+
+func TestFileScoreBoard_KickoffGame_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	board, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+
+	kickoff := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	game, err := board.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.KickoffGame(game.Id, kickoff)
+	assert.NoError(t, err)
+	assert.NoError(t, board.Close())
+
+	reopened, err := NewFileScoreBoard(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, models.StatusLive, reopened.GetGames()[0].Status)
+	assert.Equal(t, true, kickoff.Equal(reopened.GetGames()[0].StartedAt))
+}
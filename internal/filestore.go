@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// scoreBaseJournalName is the name of the journal files of FileScoreBase in the data directory.
+	scoreBaseJournalName = "scorebase"
+	// DefaultCompactionInterval is the default interval of compacting journals into snapshots.
+	DefaultCompactionInterval = 5 * time.Minute
+)
+
+// FileScoreBase is a file-backed implementation of ScoreBaseStoring.
+// Every inserted game is appended to an fsync'd append-only log before it is inserted into the in-memory ScoreBase,
+// the log is replayed on startup to rebuild the tree and is periodically compacted into a snapshot file.
+type FileScoreBase struct {
+	base    *ScoreBase
+	journal *journal
+	lock    sync.Mutex
+	logger  *log.Logger
+}
+
+// NewFileScoreBase opens the score base stored in dir, restoring all games inserted before.
+func NewFileScoreBase(dir string) (*FileScoreBase, error) {
+	base := NewScoreBase()
+
+	var snapshot, logged []*models.Game
+	j, err := openJournal(dir, scoreBaseJournalName, &snapshot, func(data json.RawMessage) error {
+		var game models.Game
+		if err := json.Unmarshal(data, &game); err != nil {
+			return err
+		}
+		logged = append(logged, &game)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range append(snapshot, logged...) {
+		_ = base.Insert(game)
+	}
+
+	return &FileScoreBase{
+		base:    base,
+		journal: j,
+		logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}, nil
+}
+
+// Insert appends the game to the log and, once it is on disk, inserts it into the in-memory tree.
+func (x *FileScoreBase) Insert(value *models.Game) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if err := x.journal.append(value); err != nil {
+		return err
+	}
+	return x.base.Insert(value)
+}
+
+// GetGame returns the stored game with the provided id from the in-memory tree.
+func (x *FileScoreBase) GetGame(id uint32) (*models.Game, error) {
+	return x.base.GetGame(id)
+}
+
+// GetGames returns all stored games from the in-memory tree.
+func (x *FileScoreBase) GetGames() []*models.Game {
+	return x.base.GetGames()
+}
+
+// Query returns a page of the stored games from the in-memory tree.
+func (x *FileScoreBase) Query(query SummaryQuery) (SummaryPage, error) {
+	return x.base.Query(query)
+}
+
+// Compact writes all stored games into the snapshot file and empties the log.
+// Nothing is done if no game was inserted since the last compaction.
+func (x *FileScoreBase) Compact() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if x.journal.pending() == 0 {
+		return nil
+	}
+	return x.journal.compact(nonNilGames(x.base.GetGames()))
+}
+
+// RunCompaction compacts the log every interval until the context is done.
+func (x *FileScoreBase) RunCompaction(ctx context.Context, interval time.Duration) {
+	runCompaction(ctx, interval, x.Compact, x.logger)
+}
+
+// Close closes the log file. The score base must not be used afterwards.
+func (x *FileScoreBase) Close() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.journal.close()
+}
+
+// runCompaction calls compact every interval until the context is done, logging failures.
+func runCompaction(ctx context.Context, interval time.Duration, compact func() error, logger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := compact(); err != nil {
+				logger.Println("failed to compact journal: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var fileStoreTestData = []*models.Game{
+	{Id: 0, HomeTeam: models.Morocco, AwayTeam: models.Canada, HomeScore: 0, AwayScore: 5},
+	{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 10, AwayScore: 2},
+	{Id: 2, HomeTeam: models.Germany, AwayTeam: models.France, HomeScore: 2, AwayScore: 2},
+	{Id: 3, HomeTeam: models.USA, AwayTeam: models.Italy, HomeScore: 6, AwayScore: 6},
+	{Id: 4, HomeTeam: models.Argentina, AwayTeam: models.Australia, HomeScore: 3, AwayScore: 1},
+}
+
+func assertSameGames(t *testing.T, want, got []*models.Game) {
+	assert.Equal(t, len(want), len(got))
+	for i := range want {
+		if i < len(got) {
+			assert.Equal(t, true, GameEquality(want[i], got[i]))
+		}
+	}
+}
+
+func TestFileScoreBase_Reopen(t *testing.T) {
+	tests := []struct {
+		name    string
+		compact bool
+	}{
+		{
+			name:    "Replaying log",
+			compact: false,
+		},
+		{
+			name:    "Reading snapshot",
+			compact: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := NewFileScoreBase(dir)
+			assert.NoError(t, err)
+			for _, game := range fileStoreTestData[:3] {
+				assert.NoError(t, store.Insert(game))
+			}
+			if tt.compact {
+				assert.NoError(t, store.Compact())
+			}
+			for _, game := range fileStoreTestData[3:] {
+				assert.NoError(t, store.Insert(game))
+			}
+			want := store.GetGames()
+			assert.NoError(t, store.Close())
+
+			reopened, err := NewFileScoreBase(dir)
+			assert.NoError(t, err)
+			defer reopened.Close()
+			assertSameGames(t, want, reopened.GetGames())
+		})
+	}
+}
+
+func TestFileScoreBase_TornRecord(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileScoreBase(dir)
+	assert.NoError(t, err)
+	for _, game := range fileStoreTestData {
+		assert.NoError(t, store.Insert(game))
+	}
+	want := store.GetGames()
+	assert.NoError(t, store.Close())
+
+	logFile, err := os.OpenFile(filepath.Join(dir, scoreBaseJournalName+journalLogSuffix), os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	_, err = logFile.WriteString(`{"seq":6,"data":{"id":5,"home_te`)
+	assert.NoError(t, err)
+	assert.NoError(t, logFile.Close())
+
+	reopened, err := NewFileScoreBase(dir)
+	assert.NoError(t, err)
+	assertSameGames(t, want, reopened.GetGames())
+
+	game := &models.Game{Id: 5, HomeTeam: models.Japan, AwayTeam: models.China, HomeScore: 1, AwayScore: 0}
+	assert.NoError(t, reopened.Insert(game))
+	assert.NoError(t, reopened.Close())
+
+	reopened, err = NewFileScoreBase(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, len(want)+1, len(reopened.GetGames()))
+}
+
+func TestFileScoreBase_CompactedRecordsAreSkipped(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileScoreBase(dir)
+	assert.NoError(t, err)
+	for _, game := range fileStoreTestData {
+		assert.NoError(t, store.Insert(game))
+	}
+	logPath := filepath.Join(dir, scoreBaseJournalName+journalLogSuffix)
+	logContent, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Compact())
+	assert.NoError(t, store.Close())
+
+	// Simulates a crash after the snapshot was written but before the log was emptied.
+	assert.NoError(t, os.WriteFile(logPath, logContent, 0o644))
+
+	reopened, err := NewFileScoreBase(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, len(fileStoreTestData), len(reopened.GetGames()))
+}
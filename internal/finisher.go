@@ -0,0 +1,295 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// FinishRecoveryInterval is the interval RunRecovery is run with by the server.
+const FinishRecoveryInterval = time.Minute
+
+const (
+	// finishJournalName is the name of the journal files of the finish intents in the data directory.
+	finishJournalName = "finish"
+	// finishCompactionThreshold is the number of journaled intents after which the finish journal is compacted.
+	finishCompactionThreshold = 256
+)
+
+const (
+	// finishOpBegin is the journal operation recording that a game is being finished.
+	finishOpBegin = "begin"
+	// finishOpCommit is the journal operation recording that a game was stored and its intent is done.
+	finishOpCommit = "commit"
+)
+
+// FinishService moves games from a GameBoard to a ScoreBaseStoring as a single operation.
+// Before a game is removed from the board, an intent holding the game is recorded, and it is cleared only after
+// the game is inserted into the store or put back on the board. An intent left behind by a crash is rolled forward,
+// either by Recover or by the next FinishGame of the same game, so a finished game never vanishes.
+type FinishService struct {
+	board   GameBoard
+	store   ScoreBaseStoring
+	intents finishIntents
+	lock    sync.Mutex
+	logger  *log.Logger
+}
+
+// finishIntents keeps the games that are being finished.
+type finishIntents interface {
+	begin(game *models.Game) error
+	commit(id uint32) error
+	pending() map[uint32]*models.Game
+}
+
+// NewFinishService returns a new instance of FinishService keeping the finish intents in memory.
+// Intents survive failed inserts, but not a restart.
+func NewFinishService(board GameBoard, store ScoreBaseStoring) *FinishService {
+	return &FinishService{
+		board:   board,
+		store:   store,
+		intents: &memoryFinishIntents{games: make(map[uint32]*models.Game)},
+		logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}
+}
+
+// NewFileFinishService returns a new instance of FinishService journaling the finish intents in dir.
+// Intents left behind by a previous run are rolled forward before it returns.
+func NewFileFinishService(board GameBoard, store ScoreBaseStoring, dir string) (*FinishService, error) {
+	intents, err := openFileFinishIntents(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &FinishService{
+		board:   board,
+		store:   store,
+		intents: intents,
+		logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}
+	if err := x.Recover(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// FinishGame removes the game with the provided id from the board, marks it as finished, inserts it into the store
+// and returns it. Games that cannot be finished in their status are left on the board. If the insert fails,
+// the game is put back on the board and its intent is cleared; only if that fails too, the game stays recorded
+// as being finished and is retried by the next call for the same id or by Recover.
+func (x *FinishService) FinishGame(id uint32) (*models.Game, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if game, ok := x.intents.pending()[id]; ok {
+		if err := x.rollForward(game); err != nil {
+			return nil, err
+		}
+		return game, nil
+	}
+
+	game, err := x.board.GetGame(id)
+	if err != nil {
+		return nil, err
+	}
+	intent := *game
+	if err := intent.Finish(); err != nil {
+		return nil, err
+	}
+	if err := x.intents.begin(&intent); err != nil {
+		return nil, fmt.Errorf("record finish intent: %w", err)
+	}
+
+	current, err := x.board.RemoveGame(id)
+	if err != nil {
+		// The game is still on the board, or it was finished concurrently, so there is nothing to roll forward.
+		if commitErr := x.intents.commit(id); commitErr != nil {
+			return nil, errors.Join(err, commitErr)
+		}
+		return nil, err
+	}
+	removed := *current
+	if err := removed.Finish(); err != nil {
+		// The game was moved to a status it cannot be finished from after it was read, so it is put back on the board.
+		// If that fails, the intent keeps the game finished as it was read, so it is not lost and only finished games are stored.
+		if restoreErr := x.board.RestoreGame(current); restoreErr != nil {
+			return nil, fmt.Errorf("game %d is kept for finishing later: %w", id, errors.Join(err, restoreErr))
+		}
+		if commitErr := x.intents.commit(id); commitErr != nil {
+			return nil, errors.Join(err, commitErr)
+		}
+		return nil, err
+	}
+	if !reflect.DeepEqual(&removed, &intent) {
+		// The game was changed between reading and removing it, the intent has to hold the final state.
+		if err := x.intents.begin(&removed); err != nil {
+			return nil, fmt.Errorf("record finish intent: %w", err)
+		}
+	}
+
+	if err := x.store.Insert(&removed); err != nil {
+		if restoreErr := x.board.RestoreGame(current); restoreErr != nil {
+			return nil, fmt.Errorf("game %d is kept for finishing later: %w", id, errors.Join(err, restoreErr))
+		}
+		if commitErr := x.intents.commit(id); commitErr != nil {
+			return nil, fmt.Errorf("game %d is kept for finishing later: %w", id, errors.Join(err, commitErr))
+		}
+		return nil, fmt.Errorf("store finished game %d: %w", id, err)
+	}
+	if err := x.intents.commit(id); err != nil {
+		return nil, fmt.Errorf("clear finish intent: %w", err)
+	}
+	return &removed, nil
+}
+
+// Recover rolls forward all games left recorded as being finished.
+func (x *FinishService) Recover() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	var errs []error
+	for _, game := range x.intents.pending() {
+		errs = append(errs, x.rollForward(game))
+	}
+	return errors.Join(errs...)
+}
+
+// RunRecovery rolls forward the games left recorded as being finished every interval until the context is done,
+// so a game whose insert failed and that could not be put back on the board is not left unfinished.
+func (x *FinishService) RunRecovery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := x.Recover(); err != nil {
+				x.logger.Println("failed to recover finished games: ", err)
+			}
+		}
+	}
+}
+
+// rollForward completes finishing of the game, skipping the steps that were done already. The lock must be held by the caller.
+func (x *FinishService) rollForward(game *models.Game) error {
+	if _, err := x.board.RemoveGame(game.Id); err != nil && !errors.Is(err, models.ErrGameNotFound) {
+		return err
+	}
+	if _, err := x.store.GetGame(game.Id); errors.Is(err, models.ErrGameNotFound) {
+		if err := x.store.Insert(game); err != nil {
+			return fmt.Errorf("game %d is kept for finishing later: %w", game.Id, err)
+		}
+	} else if err != nil {
+		return err
+	}
+	return x.intents.commit(game.Id)
+}
+
+// memoryFinishIntents keeps the finish intents in memory.
+type memoryFinishIntents struct {
+	games map[uint32]*models.Game
+}
+
+// begin records that the game is being finished, replacing a previous intent of the same game.
+func (x *memoryFinishIntents) begin(game *models.Game) error {
+	x.games[game.Id] = game
+	return nil
+}
+
+// commit clears the intent of the game with the provided id.
+func (x *memoryFinishIntents) commit(id uint32) error {
+	delete(x.games, id)
+	return nil
+}
+
+// pending returns the games that are being finished by their ids.
+func (x *memoryFinishIntents) pending() map[uint32]*models.Game {
+	return x.games
+}
+
+// finishRecord defines a single journaled operation of fileFinishIntents.
+type finishRecord struct {
+	Op   string      `json:"op"`
+	Game models.Game `json:"game"`
+}
+
+// fileFinishIntents keeps the finish intents in memory and journals them, so they survive a crash.
+type fileFinishIntents struct {
+	memoryFinishIntents
+	journal *journal
+}
+
+// openFileFinishIntents opens the finish journal stored in dir, restoring the pending intents.
+func openFileFinishIntents(dir string) (*fileFinishIntents, error) {
+	x := &fileFinishIntents{memoryFinishIntents: memoryFinishIntents{games: make(map[uint32]*models.Game)}}
+
+	var snapshot []*models.Game
+	var records []finishRecord
+	j, err := openJournal(dir, finishJournalName, &snapshot, func(data json.RawMessage) error {
+		var record finishRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, game := range snapshot {
+		x.games[game.Id] = game
+	}
+	for _, record := range records {
+		game := record.Game
+		switch record.Op {
+		case finishOpBegin:
+			x.games[game.Id] = &game
+		case finishOpCommit:
+			delete(x.games, game.Id)
+		default:
+			_ = j.close()
+			return nil, fmt.Errorf("unknown finish journal operation %q", record.Op)
+		}
+	}
+
+	x.journal = j
+	return x, nil
+}
+
+// begin journals that the game is being finished and records it in memory.
+func (x *fileFinishIntents) begin(game *models.Game) error {
+	if err := x.journal.append(finishRecord{Op: finishOpBegin, Game: *game}); err != nil {
+		return err
+	}
+	return x.memoryFinishIntents.begin(game)
+}
+
+// commit journals that the intent is done and clears it in memory.
+// The journal is compacted into the still pending intents once enough records were appended.
+func (x *fileFinishIntents) commit(id uint32) error {
+	if err := x.journal.append(finishRecord{Op: finishOpCommit, Game: models.Game{Id: id}}); err != nil {
+		return err
+	}
+	_ = x.memoryFinishIntents.commit(id)
+
+	if x.journal.pending() < finishCompactionThreshold {
+		return nil
+	}
+	games := make([]*models.Game, 0, len(x.games))
+	for _, game := range x.games {
+		games = append(games, game)
+	}
+	// The intent is committed already; a failed compaction is retried with the next commit.
+	_ = x.journal.compact(games)
+	return nil
+}
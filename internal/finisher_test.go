@@ -0,0 +1,235 @@
+package internal
+
+import (
+	"errors"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// failingScoreBase is a ScoreBaseStoring failing every insert while fail is set.
+type failingScoreBase struct {
+	*ScoreBase
+	fail bool
+}
+
+func (x *failingScoreBase) Insert(value *models.Game) error {
+	if x.fail {
+		return errors.New("disk is full")
+	}
+	return x.ScoreBase.Insert(value)
+}
+
+func TestFinishService_FinishGame(t *testing.T) {
+	board := NewScoreBoard()
+	store := NewScoreBase()
+	finisher := NewFinishService(board, store)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 2)
+	assert.NoError(t, err)
+
+	finished, err := finisher.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), finished.AwayScore)
+	assert.Equal(t, 0, len(board.GetGames()))
+	assert.Equal(t, 1, len(store.GetGames()))
+
+	_, err = finisher.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	assert.Equal(t, 1, len(store.GetGames()))
+}
+
+func TestFinishService_FailedInsertRestoresGame(t *testing.T) {
+	board := NewScoreBoard()
+	store := &failingScoreBase{ScoreBase: NewScoreBase(), fail: true}
+	finisher := NewFinishService(board, store)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 0)
+	assert.NoError(t, err)
+
+	// The game is put back on the board as it was, so it can still be read, changed and finished.
+	_, err = finisher.FinishGame(game.Id)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(store.GetGames()))
+	assert.Equal(t, 0, len(finisher.intents.pending()))
+	restored, err := board.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusLive, restored.Status)
+	assert.Equal(t, uint(1), restored.HomeScore)
+	_, err = board.StartGame("Spain", "Italy")
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+
+	store.fail = false
+	finished, err := finisher.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, game.Id, finished.Id)
+	assert.Equal(t, 0, len(board.GetGames()))
+	assert.Equal(t, 1, len(store.GetGames()))
+	assert.Equal(t, 0, len(finisher.intents.pending()))
+}
+
+// unrestorableBoard is a GameBoard failing to put games back.
+type unrestorableBoard struct {
+	*ScoreBoard
+}
+
+func (x *unrestorableBoard) RestoreGame(game *models.Game) error {
+	return models.ErrTeamAlreadyPlaying
+}
+
+func TestFinishService_FailedRestoreIsRetried(t *testing.T) {
+	board := &unrestorableBoard{ScoreBoard: NewScoreBoard()}
+	store := &failingScoreBase{ScoreBase: NewScoreBase(), fail: true}
+	finisher := NewFinishService(board, store)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	_, err = finisher.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	assert.Equal(t, 1, len(finisher.intents.pending()))
+
+	store.fail = false
+	assert.NoError(t, finisher.Recover())
+	assert.Equal(t, 1, len(store.GetGames()))
+	assert.Equal(t, 0, len(finisher.intents.pending()))
+}
+
+// racingBoard is a GameBoard moving a game through the transition right before removing it,
+// as if the transition was made concurrently with finishing the game. With failRestore it fails to put games back.
+type racingBoard struct {
+	*ScoreBoard
+	transition  models.Transition
+	failRestore bool
+}
+
+func (x *racingBoard) RestoreGame(game *models.Game) error {
+	if x.failRestore {
+		return models.ErrTeamAlreadyPlaying
+	}
+	return x.ScoreBoard.RestoreGame(game)
+}
+
+func (x *racingBoard) RemoveGame(id uint32) (*models.Game, error) {
+	if _, err := x.ScoreBoard.TransitionGame(id, x.transition); err != nil {
+		return nil, err
+	}
+	return x.ScoreBoard.RemoveGame(id)
+}
+
+func TestFinishService_FinishGame_ChangedConcurrently(t *testing.T) {
+	board := &racingBoard{ScoreBoard: NewScoreBoard(), transition: models.TransitionShootout}
+	store := NewScoreBase()
+	finisher := NewFinishService(board, store)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 1)
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(game.Id, models.TransitionExtraTime)
+	assert.NoError(t, err)
+
+	// The shootout started after the game was read, so it cannot be finished and goes back on the board.
+	_, err = finisher.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrShootoutUndecided)
+	assert.Equal(t, 0, len(store.GetGames()))
+	assert.Equal(t, 0, len(finisher.intents.pending()))
+	restored, err := board.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusPenalties, restored.Status)
+
+	// If the game cannot be put back, it is kept finished as it was read, so recovery never stores an unfinished game.
+	board.failRestore = true
+	other, err := board.StartGame("Germany", "France")
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(other.Id, models.TransitionExtraTime)
+	assert.NoError(t, err)
+	_, err = finisher.FinishGame(other.Id)
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	assert.Equal(t, 1, len(finisher.intents.pending()))
+	assert.NoError(t, finisher.Recover())
+	finished, err := store.GetGame(other.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusFinished, finished.Status)
+}
+
+func TestFileFinishService_Recover(t *testing.T) {
+	dir := t.TempDir()
+	board := NewScoreBoard()
+	store := NewScoreBase()
+	finisher, err := NewFileFinishService(board, store, dir)
+	assert.NoError(t, err)
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	game, err = board.UpdateGame(game.Id, 4, 0)
+	assert.NoError(t, err)
+	intent := *game
+	assert.NoError(t, intent.Finish())
+	assert.NoError(t, finisher.intents.begin(&intent))
+	_, err = board.RemoveGame(game.Id)
+	assert.NoError(t, err)
+	assert.NoError(t, finisher.intents.(*fileFinishIntents).journal.close())
+
+	// Simulates a restart after the game was removed from the board but before it was stored.
+	restarted, err := NewFileFinishService(board, store, dir)
+	assert.NoError(t, err)
+	games := store.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, uint(4), games[0].HomeScore)
+	assert.Equal(t, 0, len(restarted.intents.pending()))
+
+	// Recovering again must not store the game twice.
+	assert.NoError(t, restarted.Recover())
+	assert.Equal(t, 1, len(store.GetGames()))
+}
+
+func TestApp_EndGame_UsesFinisher(t *testing.T) {
+	board := NewScoreBoard()
+	store := &failingScoreBase{ScoreBase: NewScoreBase(), fail: true}
+	app := NewApp(store, board)
+	app.InitRoutes()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	store.fail = false
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, game.Id, store.GetGames()[0].Id)
+}
+
+func TestFinishService_FinishGame_Status(t *testing.T) {
+	board := NewScoreBoard()
+	store := NewScoreBase()
+	finisher := NewFinishService(board, store)
+
+	scheduled, err := board.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = finisher.FinishGame(scheduled.Id)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	assert.Equal(t, 1, len(board.GetGames()))
+
+	_, err = board.TransitionGame(scheduled.Id, models.TransitionKickoff)
+	assert.NoError(t, err)
+	finished, err := finisher.FinishGame(scheduled.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusFinished, finished.Status)
+
+	abandoned, err := board.StartGame("USA", "Italy")
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(abandoned.Id, models.TransitionAbandon)
+	assert.NoError(t, err)
+	finished, err = finisher.FinishGame(abandoned.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusAbandoned, finished.Status)
+	assert.Equal(t, 2, len(store.GetGames()))
+}
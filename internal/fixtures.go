@@ -0,0 +1,353 @@
+package internal
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fixturesJournalName is the name of the journal files of FileFixtures in the data directory.
+	fixturesJournalName = "fixtures"
+	// fixturesCompactionThreshold is the number of journaled operations after which the fixtures journal is compacted.
+	fixturesCompactionThreshold = 256
+)
+
+const (
+	// fixtureOpAdd is the journal operation of adding a fixture.
+	fixtureOpAdd = "add"
+	// fixtureOpBeginPromote is the journal operation of starting the game of a fixture at kickoff.
+	fixtureOpBeginPromote = "begin_promote"
+	// fixtureOpPromote is the journal operation of promoting a fixture to a game.
+	fixtureOpPromote = "promote"
+)
+
+// FixtureStoring defines methods for keeping fixtures until they are promoted to games at kickoff.
+// AddFixture validates the teams and stores a new upcoming fixture, Upcoming returns the fixtures that were not
+// promoted yet ordered by kickoff time, and Promote records the game the fixture with the provided id was promoted to,
+// removing it from the upcoming fixtures. BeginPromote marks the upcoming fixture as Promoting before its game is
+// started, so a game started before the process stopped is looked up again instead of being started twice. Promoted returns the fixture promoted to the game with the provided id, so
+// e.g. its venue can be shown with the game, and models.ErrFixtureNotFound for a game not started from a fixture.
+type FixtureStoring interface {
+	AddFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error)
+	Upcoming() ([]*models.Fixture, error)
+	BeginPromote(id uint32) error
+	Promote(id, gameId uint32) error
+	Promoted(gameId uint32) (*models.Fixture, error)
+}
+
+// newFixture validates the teams and the kickoff time and returns a new fixture without an id.
+func newFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error) {
+	homeTeamCountry, err := models.ResolveTeam(homeTeam)
+	if err != nil {
+		return nil, err
+	}
+	awayTeamCountry, err := models.ResolveTeam(awayTeam)
+	if err != nil {
+		return nil, err
+	}
+	if homeTeamCountry == awayTeamCountry {
+		return nil, models.ErrSameTeam
+	}
+	if kickoffAt.IsZero() {
+		return nil, models.ErrInvalidKickoff
+	}
+
+	return &models.Fixture{
+		HomeTeam:  homeTeamCountry,
+		AwayTeam:  awayTeamCountry,
+		KickoffAt: kickoffAt,
+		Venue:     strings.TrimSpace(venue),
+	}, nil
+}
+
+// byKickoff orders fixtures by the kickoff time, then by id.
+func byKickoff(a, b *models.Fixture) int {
+	if c := a.KickoffAt.Compare(b.KickoffAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Id, b.Id)
+}
+
+// MemoryFixtures keeps the upcoming fixtures in memory by id and the promoted ones by the id of their game.
+type MemoryFixtures struct {
+	lock     sync.RWMutex
+	nextId   uint32
+	fixtures map[uint32]*models.Fixture
+	promoted map[uint32]*models.Fixture
+}
+
+// NewMemoryFixtures returns a new instance of MemoryFixtures without fixtures.
+func NewMemoryFixtures() *MemoryFixtures {
+	return &MemoryFixtures{
+		fixtures: make(map[uint32]*models.Fixture),
+		promoted: make(map[uint32]*models.Fixture),
+	}
+}
+
+// AddFixture stores a new upcoming fixture with the next id.
+func (x *MemoryFixtures) AddFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error) {
+	fixture, err := newFixture(homeTeam, awayTeam, kickoffAt, venue)
+	if err != nil {
+		return nil, err
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.nextId++
+	fixture.Id = x.nextId
+	x.fixtures[fixture.Id] = fixture
+	return fixture, nil
+}
+
+// Upcoming returns the fixtures that were not promoted yet ordered by kickoff time.
+func (x *MemoryFixtures) Upcoming() ([]*models.Fixture, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	result := make([]*models.Fixture, 0, len(x.fixtures))
+	for _, fixture := range x.fixtures {
+		result = append(result, fixture)
+	}
+	slices.SortFunc(result, byKickoff)
+	return result, nil
+}
+
+// BeginPromote marks the upcoming fixture as Promoting on a copy, so fixtures returned by Upcoming earlier are never changed.
+func (x *MemoryFixtures) BeginPromote(id uint32) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	fixture, ok := x.fixtures[id]
+	if !ok {
+		return models.ErrFixtureNotFound
+	}
+	x.beginPromote(fixture)
+	return nil
+}
+
+// beginPromote replaces the upcoming fixture with a copy marked as Promoting. The lock must be held by the caller.
+func (x *MemoryFixtures) beginPromote(fixture *models.Fixture) {
+	promoting := *fixture
+	promoting.Promoting = true
+	x.fixtures[fixture.Id] = &promoting
+}
+
+// Promote moves the fixture from the upcoming fixtures to the promoted ones, keeping it with the id of the game.
+func (x *MemoryFixtures) Promote(id, gameId uint32) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	fixture, ok := x.fixtures[id]
+	if !ok {
+		return models.ErrFixtureNotFound
+	}
+	x.promote(fixture, gameId)
+	return nil
+}
+
+// promote moves a copy of the fixture with the game id set to the promoted fixtures,
+// so fixtures returned by Upcoming earlier are never changed. The lock must be held by the caller.
+func (x *MemoryFixtures) promote(fixture *models.Fixture, gameId uint32) {
+	promoted := *fixture
+	promoted.GameId = gameId
+	promoted.Promoting = false
+	delete(x.fixtures, fixture.Id)
+	x.promoted[gameId] = &promoted
+}
+
+// Promoted returns the fixture promoted to the game with the provided id.
+func (x *MemoryFixtures) Promoted(gameId uint32) (*models.Fixture, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	fixture, ok := x.promoted[gameId]
+	if !ok {
+		return nil, models.ErrFixtureNotFound
+	}
+	return fixture, nil
+}
+
+// allPromoted returns the promoted fixtures ordered by kickoff time.
+func (x *MemoryFixtures) allPromoted() []*models.Fixture {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	result := make([]*models.Fixture, 0, len(x.promoted))
+	for _, fixture := range x.promoted {
+		result = append(result, fixture)
+	}
+	slices.SortFunc(result, byKickoff)
+	return result
+}
+
+// fixtureRecord defines a single journaled operation of FileFixtures.
+type fixtureRecord struct {
+	Op      string         `json:"op"`
+	Fixture models.Fixture `json:"fixture"`
+}
+
+// fixturesSnapshot defines the snapshot of FileFixtures. NextId keeps the id counter,
+// because the fixtures holding the highest ids may have been promoted already.
+// Promoted keeps the promoted fixtures with the ids of their games.
+type fixturesSnapshot struct {
+	NextId   uint32            `json:"next_id"`
+	Fixtures []*models.Fixture `json:"fixtures"`
+	Promoted []*models.Fixture `json:"promoted,omitempty"`
+}
+
+// FileFixtures keeps the upcoming and promoted fixtures in memory and journals every change before it is visible,
+// so fixtures survive a restart. The journal is compacted into the upcoming fixtures once enough operations were appended.
+type FileFixtures struct {
+	memory  *MemoryFixtures
+	journal *journal
+	lock    sync.Mutex
+}
+
+// NewFileFixtures opens the fixtures stored in dir, restoring the upcoming and promoted fixtures and the id counter.
+func NewFileFixtures(dir string) (*FileFixtures, error) {
+	memory := NewMemoryFixtures()
+
+	var snapshot fixturesSnapshot
+	var records []fixtureRecord
+	j, err := openJournal(dir, fixturesJournalName, &snapshot, func(data json.RawMessage) error {
+		var record fixtureRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	memory.nextId = snapshot.NextId
+	for _, fixture := range snapshot.Fixtures {
+		memory.fixtures[fixture.Id] = fixture
+	}
+	for _, fixture := range snapshot.Promoted {
+		memory.promoted[fixture.GameId] = fixture
+	}
+	for _, record := range records {
+		fixture := record.Fixture
+		memory.nextId = max(memory.nextId, fixture.Id)
+		switch record.Op {
+		case fixtureOpAdd:
+			memory.fixtures[fixture.Id] = &fixture
+		case fixtureOpBeginPromote:
+			if upcoming, ok := memory.fixtures[fixture.Id]; ok {
+				memory.beginPromote(upcoming)
+			}
+		case fixtureOpPromote:
+			if upcoming, ok := memory.fixtures[fixture.Id]; ok {
+				memory.promote(upcoming, fixture.GameId)
+			}
+		default:
+			_ = j.close()
+			return nil, fmt.Errorf("unknown fixtures journal operation %q", record.Op)
+		}
+	}
+
+	return &FileFixtures{
+		memory:  memory,
+		journal: j,
+	}, nil
+}
+
+// AddFixture journals a new upcoming fixture with the next id and stores it in memory.
+func (x *FileFixtures) AddFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error) {
+	fixture, err := newFixture(homeTeam, awayTeam, kickoffAt, venue)
+	if err != nil {
+		return nil, err
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	fixture.Id = x.memory.nextId + 1
+	if err := x.journal.append(fixtureRecord{Op: fixtureOpAdd, Fixture: *fixture}); err != nil {
+		return nil, err
+	}
+	x.memory.lock.Lock()
+	x.memory.nextId = fixture.Id
+	x.memory.fixtures[fixture.Id] = fixture
+	x.memory.lock.Unlock()
+
+	x.compactIfNeeded()
+	return fixture, nil
+}
+
+// Upcoming returns the fixtures that were not promoted yet ordered by kickoff time.
+func (x *FileFixtures) Upcoming() ([]*models.Fixture, error) {
+	return x.memory.Upcoming()
+}
+
+// BeginPromote journals that the game of the upcoming fixture is being started and marks the fixture as Promoting.
+func (x *FileFixtures) BeginPromote(id uint32) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.memory.lock.RLock()
+	_, ok := x.memory.fixtures[id]
+	x.memory.lock.RUnlock()
+	if !ok {
+		return models.ErrFixtureNotFound
+	}
+	if err := x.journal.append(fixtureRecord{Op: fixtureOpBeginPromote, Fixture: models.Fixture{Id: id}}); err != nil {
+		return err
+	}
+	_ = x.memory.BeginPromote(id)
+
+	x.compactIfNeeded()
+	return nil
+}
+
+// Promote journals that the fixture was promoted to the game and moves it from the upcoming fixtures to the promoted ones.
+func (x *FileFixtures) Promote(id, gameId uint32) error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.memory.lock.RLock()
+	_, ok := x.memory.fixtures[id]
+	x.memory.lock.RUnlock()
+	if !ok {
+		return models.ErrFixtureNotFound
+	}
+	if err := x.journal.append(fixtureRecord{Op: fixtureOpPromote, Fixture: models.Fixture{Id: id, GameId: gameId}}); err != nil {
+		return err
+	}
+	_ = x.memory.Promote(id, gameId)
+
+	x.compactIfNeeded()
+	return nil
+}
+
+// Promoted returns the fixture promoted to the game with the provided id.
+func (x *FileFixtures) Promoted(gameId uint32) (*models.Fixture, error) {
+	return x.memory.Promoted(gameId)
+}
+
+// compactIfNeeded compacts the journal into the upcoming and promoted fixtures once enough operations were appended.
+// A failed compaction is retried with the next operation. The lock must be held by the caller.
+func (x *FileFixtures) compactIfNeeded() {
+	if x.journal.pending() < fixturesCompactionThreshold {
+		return
+	}
+	upcoming, _ := x.memory.Upcoming()
+	_ = x.journal.compact(fixturesSnapshot{NextId: x.memory.nextId, Fixtures: upcoming, Promoted: x.memory.allPromoted()})
+}
+
+// Close closes the log file. The fixtures must not be used afterwards.
+func (x *FileFixtures) Close() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.journal.close()
+}
@@ -11,62 +11,165 @@ import (
 // 1. Inserting an element, the complexity of this operation in BTS is O(log(N))
 // 2. Getting all elements in sorted form, the complexity of this operation in BTS is O(N),
 // since the structure and the insertion operation imply storing the data in sorted form.
+// The tree is kept balanced as an AVL tree, so the complexity of inserting stays O(log(N)) even when
+// games are inserted in sorted order, e.g. a run of 0:0 draws, which degenerates a plain BST into a linked list.
+// Games are kept in the order defined by the GameComparator of the ScoreBase; for ByScoreThenRecency the tree is
+// keyed by the composite (score, start time, id) key.
 type ScoreBase struct {
-	Root *GameNode
-	lock sync.RWMutex
+	Root    *GameNode
+	order   GameComparator
+	byScore bool
+	games   map[uint32]*models.Game
+	lock    sync.RWMutex
 }
 
-// GameNode represents a node in BTS(binary search tree) with a pointer to a game entity and left and right child nodes.
+// GameNode represents a node in BTS(binary search tree) with a pointer to a game entity, left and right child nodes
+// and the height of the subtree rooted at the node, used for balancing.
 type GameNode struct {
-	Value *models.Game
-	Left  *GameNode
-	Right *GameNode
+	Value  *models.Game
+	Left   *GameNode
+	Right  *GameNode
+	height int
 }
 
-// NewScoreBase returns a new instance of ScoreBase with a nil root node and a sync RWMutex.
+// NewScoreBase returns a new instance of ScoreBase with a nil root node, a sync RWMutex and the ByScoreThenRecency ordering.
 func NewScoreBase() *ScoreBase {
+	x := NewScoreBaseWithOrder(ByScoreThenRecency)
+	x.byScore = true
+	return x
+}
+
+// NewScoreBaseWithOrder returns a new instance of ScoreBase keeping games in the order defined by the provided comparator.
+func NewScoreBaseWithOrder(order GameComparator) *ScoreBase {
 	return &ScoreBase{
-		Root: nil,
-		lock: sync.RWMutex{},
+		Root:  nil,
+		order: order,
+		games: make(map[uint32]*models.Game),
+		lock:  sync.RWMutex{},
 	}
 }
 
-// ScoreBaseStoring defines methods for storing game scores, including inserting a new game and getting all stored games.
+// ScoreBaseStoring defines methods for storing game scores, including inserting a new game, getting a stored game by id,
+// getting all stored games and querying a page of the stored games.
+// GetGame returns models.ErrGameNotFound for a game that is not stored.
+// Insert returns an error when the game could not be stored, e.g. by implementations backed by a file.
 type ScoreBaseStoring interface {
-	Insert(value *models.Game)
+	Insert(value *models.Game) error
+	GetGame(id uint32) (*models.Game, error)
 	GetGames() []*models.Game
+	Query(query SummaryQuery) (SummaryPage, error)
+}
+
+// SummaryQuery defines a query of a page of stored games, in the same order as returned by GetGames.
+type SummaryQuery struct {
+	// Limit is the maximum number of returned games, zero means no limit. The top N games are queried with Limit N.
+	Limit int
+	// After is the id of the last game of the previous page, zero means the first page.
+	After uint32
+	// MinScore and MaxScore limit the total score of the returned games, inclusive. Nil means no limit.
+	MinScore *uint
+	MaxScore *uint
+}
+
+// SummaryPage defines the result of a SummaryQuery.
+type SummaryPage struct {
+	Games []*models.Game
+	// Next is the cursor for querying the following page, zero if there are no more games.
+	Next uint32
 }
 
 // Insert adds a new game node with the provided game data to the binary search tree.
-// If the root node is nil, the new node becomes the root; otherwise, it is inserted following the BST rules.
-func (x *ScoreBase) Insert(value *models.Game) {
+// If the root node is nil, the new node becomes the root; otherwise, it is inserted following the BST rules
+// and the tree is rebalanced.
+// Inserting into memory cannot fail, so the returned error is always nil.
+func (x *ScoreBase) Insert(value *models.Game) error {
 	x.lock.Lock()
 	defer x.lock.Unlock()
 
-	newNode := &GameNode{Value: value}
-	if x.Root == nil {
-		x.Root = newNode
+	x.Root = insertNode(x.Root, &GameNode{Value: value, height: 1}, x.order)
+	x.games[value.Id] = value
+	return nil
+}
+
+// insertNode adds a newNode to the binary search tree starting from the given node following the BST rules
+// and returns the new root of the subtree, rebalanced on the way back up.
+// If the newNode comes before the node's game or is equal to it according to order, it is inserted to the left; otherwise, to the right.
+func insertNode(node, newNode *GameNode, order GameComparator) *GameNode {
+	if node == nil {
+		return newNode
+	}
+	if order(newNode.Value, node.Value) <= 0 {
+		node.Left = insertNode(node.Left, newNode, order)
 	} else {
-		insertNode(x.Root, newNode)
+		node.Right = insertNode(node.Right, newNode, order)
 	}
+	return rebalance(node)
 }
 
-// insertNode adds a newNode to the binary search tree starting from the given node following the BST rules.
-// If the newNode score is greater than or equal to the node's score, it is inserted to the left; otherwise, to the right.
-func insertNode(node, newNode *GameNode) {
-	if newNode.Value.GetScore() > node.Value.GetScore() || newNode.Value.GetScore() == node.Value.GetScore() {
-		if node.Left == nil {
-			node.Left = newNode
-		} else {
-			insertNode(node.Left, newNode)
+// height returns the height of the subtree rooted at the node, zero for a nil node.
+func height(node *GameNode) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+// updateHeight recalculates the height of the node from the heights of its children.
+func updateHeight(node *GameNode) {
+	node.height = 1 + max(height(node.Left), height(node.Right))
+}
+
+// rebalance restores the AVL property of the node, whose children are balanced already,
+// with at most two rotations and returns the new root of the subtree.
+func rebalance(node *GameNode) *GameNode {
+	updateHeight(node)
+	balance := height(node.Left) - height(node.Right)
+	switch {
+	case balance > 1:
+		if height(node.Left.Left) < height(node.Left.Right) {
+			node.Left = rotateLeft(node.Left)
 		}
-	} else {
-		if node.Right == nil {
-			node.Right = newNode
-		} else {
-			insertNode(node.Right, newNode)
+		return rotateRight(node)
+	case balance < -1:
+		if height(node.Right.Right) < height(node.Right.Left) {
+			node.Right = rotateRight(node.Right)
 		}
+		return rotateLeft(node)
+	default:
+		return node
+	}
+}
+
+// rotateLeft rotates the subtree rooted at the node to the left and returns its new root.
+func rotateLeft(node *GameNode) *GameNode {
+	root := node.Right
+	node.Right = root.Left
+	root.Left = node
+	updateHeight(node)
+	updateHeight(root)
+	return root
+}
+
+// rotateRight rotates the subtree rooted at the node to the right and returns its new root.
+func rotateRight(node *GameNode) *GameNode {
+	root := node.Left
+	node.Left = root.Right
+	root.Right = node
+	updateHeight(node)
+	updateHeight(root)
+	return root
+}
+
+// GetGame returns the stored game with the provided id, looked up in the index of games by id.
+func (x *ScoreBase) GetGame(id uint32) (*models.Game, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	game, ok := x.games[id]
+	if !ok {
+		return nil, models.ErrGameNotFound
 	}
+	return game, nil
 }
 
 // GetGames returns a slice of all games stored in the binary search tree.
@@ -85,6 +188,7 @@ func (x *ScoreBase) GetGames() []*models.Game {
 
 // inOrderTraverse performs in-order traversal on a binary search tree starting
 // from the given node and returns a slice of games in sorted order.
+// Since the tree is balanced, the depth of the recursion is O(log(N)).
 // If the node is nil, an empty slice is returned.
 func inOrderTraverse(node *GameNode, result []*models.Game) []*models.Game {
 	if node != nil {
@@ -94,3 +198,68 @@ func inOrderTraverse(node *GameNode, result []*models.Game) []*models.Game {
 	}
 	return result
 }
+
+// Query returns a page of the stored games matching the query.
+// The tree is searched for the first game after the cursor, so a page costs O(log(N) + Limit).
+// When the games are ordered by score, as they are by NewScoreBase, the score range is searched for as well
+// and the walk stops at the first game below MinScore; for other orderings games outside the range are skipped.
+func (x *ScoreBase) Query(query SummaryQuery) (SummaryPage, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	from := func(*models.Game) bool { return true }
+	if query.After != 0 {
+		cursor, ok := x.games[query.After]
+		if !ok {
+			return SummaryPage{}, models.ErrInvalidCursor
+		}
+		from = func(game *models.Game) bool { return x.order(game, cursor) > 0 }
+	}
+	if x.byScore && query.MaxScore != nil {
+		afterCursor := from
+		from = func(game *models.Game) bool { return afterCursor(game) && game.GetScore() <= *query.MaxScore }
+	}
+
+	var page SummaryPage
+	walkFrom(x.Root, from, func(game *models.Game) bool {
+		if query.MinScore != nil && game.GetScore() < *query.MinScore {
+			return !x.byScore
+		}
+		if query.MaxScore != nil && game.GetScore() > *query.MaxScore {
+			return true
+		}
+		if query.Limit > 0 && len(page.Games) == query.Limit {
+			page.Next = page.Games[len(page.Games)-1].Id
+			return false
+		}
+		page.Games = append(page.Games, game)
+		return true
+	})
+	return page, nil
+}
+
+// walkFrom visits the games of the tree in order, starting with the first game for which from returns true,
+// until visit returns false. The from function must return false for a prefix of the games and true for the rest.
+// It is iterative and only keeps the path from the root to the current node, so it costs O(log(N)) memory.
+func walkFrom(root *GameNode, from func(*models.Game) bool, visit func(*models.Game) bool) {
+	var stack []*GameNode
+	for node := root; node != nil; {
+		if from(node.Value) {
+			stack = append(stack, node)
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !visit(node.Value) {
+			return
+		}
+		for next := node.Right; next != nil; next = next.Left {
+			stack = append(stack, next)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package internal
 
 import (
+	"fmt"
 	"github.com/Marian2701/CodingExercise/internal/models"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestScoreBase_Insert(t *testing.T) {
@@ -78,3 +81,217 @@ func GameEquality(game1, game2 *models.Game) bool {
 		game1.HomeScore == game2.HomeScore &&
 		game1.AwayScore == game2.AwayScore
 }
+
+func TestScoreBase_Insert_TiesByStartTime(t *testing.T) {
+	start := time.Date(2026, 6, 1, 18, 0, 0, 0, time.UTC)
+	games := []*models.Game{
+		{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 1, AwayScore: 1, StartedAt: start.Add(2 * time.Hour)},
+		{Id: 2, HomeTeam: models.USA, AwayTeam: models.Italy, HomeScore: 2, AwayScore: 0, StartedAt: start},
+		{Id: 3, HomeTeam: models.Germany, AwayTeam: models.France, HomeScore: 0, AwayScore: 2, StartedAt: start.Add(time.Hour)},
+		{Id: 4, HomeTeam: models.Japan, AwayTeam: models.China, HomeScore: 3, AwayScore: 0, StartedAt: start},
+		{Id: 5, HomeTeam: models.Poland, AwayTeam: models.Egypt, HomeScore: 1, AwayScore: 1, StartedAt: start},
+	}
+
+	tests := []struct {
+		name    string
+		order   GameComparator
+		wantIds []uint32
+	}{
+		{
+			name:    "Score, then most recently started, then id",
+			order:   ByScoreThenRecency,
+			wantIds: []uint32{4, 1, 3, 5, 2},
+		},
+		{
+			name: "Custom ordering by id",
+			order: func(a, b *models.Game) int {
+				return int(a.Id) - int(b.Id)
+			},
+			wantIds: []uint32{1, 2, 3, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := NewScoreBaseWithOrder(tt.order)
+			for _, game := range games {
+				assert.NoError(t, sb.Insert(game))
+			}
+			var gotIds []uint32
+			for _, game := range sb.GetGames() {
+				gotIds = append(gotIds, game.Id)
+			}
+			assert.Equal(t, tt.wantIds, gotIds)
+		})
+	}
+}
+
+// checkAVL verifies the ordering, the stored heights and the balance of the subtree rooted at the node and returns its height.
+func checkAVL(t *testing.T, node *GameNode, order GameComparator) int {
+	if node == nil {
+		return 0
+	}
+	if node.Left != nil {
+		assert.LessOrEqual(t, order(node.Left.Value, node.Value), 0)
+	}
+	if node.Right != nil {
+		assert.Greater(t, order(node.Right.Value, node.Value), 0)
+	}
+	left := checkAVL(t, node.Left, order)
+	right := checkAVL(t, node.Right, order)
+	assert.LessOrEqual(t, left-right, 1)
+	assert.GreaterOrEqual(t, left-right, -1)
+	assert.Equal(t, 1+max(left, right), node.height)
+	return node.height
+}
+
+func TestScoreBase_Insert_Balanced(t *testing.T) {
+	numOfDraws := 50000
+	sb := NewScoreBase()
+	for i := 0; i < numOfDraws; i++ {
+		assert.NoError(t, sb.Insert(&models.Game{Id: uint32(i + 1), HomeTeam: models.Spain, AwayTeam: models.Brazil}))
+	}
+
+	height := checkAVL(t, sb.Root, sb.order)
+	// The height of an AVL tree is below 1.45*log2(N+2).
+	assert.Less(t, float64(height), 1.45*math.Log2(float64(numOfDraws+2)))
+
+	games := sb.GetGames()
+	assert.Equal(t, numOfDraws, len(games))
+	for i, game := range games {
+		assert.Equal(t, uint32(numOfDraws-i), game.Id)
+	}
+}
+
+func BenchmarkScoreBase_Insert_Draws(b *testing.B) {
+	for _, numOfGames := range []int{1000, 10000, 50000} {
+		games := make([]*models.Game, numOfGames)
+		for i := range games {
+			games[i] = &models.Game{Id: uint32(i + 1), HomeTeam: models.Spain, AwayTeam: models.Brazil}
+		}
+
+		b.Run(fmt.Sprintf("%d draws", numOfGames), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sb := NewScoreBase()
+				for _, game := range games {
+					_ = sb.Insert(game)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*numOfGames), "ns/insert")
+		})
+	}
+}
+
+// queryTestData returns the games used by the Query tests, ordered by score 12, 12, 5, 4, 4 with ids 4, 2, 1, 5, 3.
+func queryTestData() []*models.Game {
+	return []*models.Game{
+		{Id: 1, HomeTeam: models.Morocco, AwayTeam: models.Canada, HomeScore: 0, AwayScore: 5},
+		{Id: 2, HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 10, AwayScore: 2},
+		{Id: 3, HomeTeam: models.Germany, AwayTeam: models.France, HomeScore: 2, AwayScore: 2},
+		{Id: 4, HomeTeam: models.USA, AwayTeam: models.Italy, HomeScore: 6, AwayScore: 6},
+		{Id: 5, HomeTeam: models.Argentina, AwayTeam: models.Australia, HomeScore: 3, AwayScore: 1},
+	}
+}
+
+// queryTests returns the cases shared by the Query tests of all ScoreBaseStoring implementations.
+func queryTests() []struct {
+	name     string
+	query    SummaryQuery
+	wantIds  []uint32
+	wantNext uint32
+} {
+	score := func(n uint) *uint { return &n }
+	return []struct {
+		name     string
+		query    SummaryQuery
+		wantIds  []uint32
+		wantNext uint32
+	}{
+		{
+			name:    "All games",
+			query:   SummaryQuery{},
+			wantIds: []uint32{4, 2, 1, 5, 3},
+		},
+		{
+			name:     "Top 2",
+			query:    SummaryQuery{Limit: 2},
+			wantIds:  []uint32{4, 2},
+			wantNext: 2,
+		},
+		{
+			name:     "Second page",
+			query:    SummaryQuery{Limit: 2, After: 2},
+			wantIds:  []uint32{1, 5},
+			wantNext: 5,
+		},
+		{
+			name:    "Last page",
+			query:   SummaryQuery{Limit: 2, After: 5},
+			wantIds: []uint32{3},
+		},
+		{
+			name:    "Page exactly at the end",
+			query:   SummaryQuery{Limit: 3, After: 2},
+			wantIds: []uint32{1, 5, 3},
+		},
+		{
+			name:    "Score range",
+			query:   SummaryQuery{MinScore: score(4), MaxScore: score(5)},
+			wantIds: []uint32{1, 5, 3},
+		},
+		{
+			name:     "Score range with cursor",
+			query:    SummaryQuery{Limit: 1, After: 1, MinScore: score(4), MaxScore: score(5)},
+			wantIds:  []uint32{5},
+			wantNext: 5,
+		},
+		{
+			name:    "Empty score range",
+			query:   SummaryQuery{MinScore: score(6), MaxScore: score(11)},
+			wantIds: nil,
+		},
+	}
+}
+
+// gameIds returns the ids of the games in order.
+func gameIds(games []*models.Game) []uint32 {
+	var ids []uint32
+	for _, game := range games {
+		ids = append(ids, game.Id)
+	}
+	return ids
+}
+
+func TestScoreBase_GetGame(t *testing.T) {
+	base := NewScoreBase()
+	for _, game := range fileStoreTestData {
+		assert.NoError(t, base.Insert(game))
+	}
+
+	for _, want := range fileStoreTestData {
+		game, err := base.GetGame(want.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, game)
+	}
+	_, err := base.GetGame(99)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+}
+
+func TestScoreBase_Query(t *testing.T) {
+	base := NewScoreBase()
+	for _, game := range queryTestData() {
+		assert.NoError(t, base.Insert(game))
+	}
+
+	for _, tt := range queryTests() {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := base.Query(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIds, gameIds(page.Games))
+			assert.Equal(t, tt.wantNext, page.Next)
+		})
+	}
+
+	_, err := base.Query(SummaryQuery{After: 99})
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
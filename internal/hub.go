@@ -0,0 +1,258 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	"os"
+	"sync"
+)
+
+// clientQueueSize is the number of messages queued for a single hub client.
+// A client whose queue is full is disconnected, so it cannot stall updates for the other clients.
+const clientQueueSize = 32
+
+// Hub is a publish/subscribe hub delivering score deltas of the games on the board to clients
+// that subscribed to particular match ids or teams. It is fed by the events of the EventBroker.
+type Hub struct {
+	events  *EventBroker
+	sub     *Subscription
+	lock    sync.Mutex
+	clients map[*HubClient]struct{}
+	scores  map[uint32]ScoreDelta
+	logger  *log.Logger
+}
+
+// HubClient represents a single client of the Hub with its subscriptions and bounded send queue.
+// Messages are delivered on Send, which is closed when the client is disconnected by the hub.
+type HubClient struct {
+	Send    <-chan []byte
+	send    chan []byte
+	matches map[uint32]struct{}
+	teams   map[models.Countries]struct{}
+	closed  bool
+}
+
+// ScoreDelta defines the change of a score of a game, or the score itself when stored by the hub.
+type ScoreDelta struct {
+	Home int `json:"home"`
+	Away int `json:"away"`
+}
+
+// HubMessage defines the message sent by the hub to its clients.
+type HubMessage struct {
+	Type    string           `json:"type"`
+	EventId uint64           `json:"event_id,omitempty"`
+	MatchId uint32           `json:"match_id,omitempty"`
+	Team    models.Countries `json:"team,omitempty"`
+	Game    *models.Game     `json:"game,omitempty"`
+	Delta   *ScoreDelta      `json:"delta,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// NewHub returns a new instance of Hub fed by the provided EventBroker.
+// The hub subscribes to the broker right away, so no events published before Run is called are missed.
+func NewHub(events *EventBroker) *Hub {
+	sub, _ := events.Subscribe(0)
+	return &Hub{
+		events:  events,
+		sub:     sub,
+		clients: make(map[*HubClient]struct{}),
+		scores:  make(map[uint32]ScoreDelta),
+		logger:  log.New(os.Stdout, "", log.LstdFlags),
+	}
+}
+
+// Run consumes events of the broker and dispatches them to the subscribed clients until the context is done.
+// If the hub itself falls behind the broker, it subscribes again and replays the missed events.
+func (x *Hub) Run(ctx context.Context) {
+	var lastId uint64
+	sub := x.sub
+	for {
+		if !x.consume(ctx, sub, &lastId) {
+			sub.Close()
+			x.disconnectAll()
+			return
+		}
+		x.logger.Println("hub fell behind the event broker, resubscribing from event ", lastId)
+
+		var missed []Event
+		sub, missed = x.events.Subscribe(lastId)
+		for _, event := range missed {
+			x.dispatch(event)
+			lastId = event.Id
+		}
+	}
+}
+
+// consume dispatches events of the subscription until it is dropped by the broker, which returns true,
+// or until the context is done, which returns false.
+func (x *Hub) consume(ctx context.Context, sub *Subscription, lastId *uint64) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-sub.C:
+			if !ok {
+				return true
+			}
+			x.dispatch(event)
+			*lastId = event.Id
+		}
+	}
+}
+
+// Connect registers a new client without subscriptions.
+func (x *Hub) Connect() *HubClient {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	send := make(chan []byte, clientQueueSize)
+	client := &HubClient{
+		Send:    send,
+		send:    send,
+		matches: make(map[uint32]struct{}),
+		teams:   make(map[models.Countries]struct{}),
+	}
+	x.clients[client] = struct{}{}
+	return client
+}
+
+// Disconnect removes the client from the hub and closes its send queue.
+// It is safe to call Disconnect on an already disconnected client.
+func (x *Hub) Disconnect(client *HubClient) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.disconnect(client)
+}
+
+// SubscribeMatch subscribes the client to the changes of the game with the provided id.
+func (x *Hub) SubscribeMatch(client *HubClient, id uint32) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	client.matches[id] = struct{}{}
+}
+
+// UnsubscribeMatch removes the subscription of the client to the game with the provided id.
+func (x *Hub) UnsubscribeMatch(client *HubClient, id uint32) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	delete(client.matches, id)
+}
+
+// SubscribeTeam subscribes the client to the changes of all games of the provided team.
+func (x *Hub) SubscribeTeam(client *HubClient, team models.Countries) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	client.teams[team] = struct{}{}
+}
+
+// UnsubscribeTeam removes the subscription of the client to the games of the provided team.
+func (x *Hub) UnsubscribeTeam(client *HubClient, team models.Countries) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	delete(client.teams, team)
+}
+
+// Reply queues a message for a single client, e.g. an acknowledgement of a subscription.
+func (x *Hub) Reply(client *HubClient, message HubMessage) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		x.logger.Println("failed to encode hub message: ", err)
+		return
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.enqueue(client, data)
+}
+
+// dispatch computes the score delta of the event and queues it for every client subscribed to the game or its teams.
+func (x *Hub) dispatch(event Event) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	game := event.Game
+	message := HubMessage{
+		Type:    string(event.Type),
+		EventId: event.Id,
+		MatchId: game.Id,
+		Game:    &game,
+	}
+
+	// Finished games recorded into the score base are no longer on the board, so there is no delta to report.
+	if event.Type != EventMatchRecorded {
+		current := ScoreDelta{Home: int(game.HomeScore), Away: int(game.AwayScore)}
+		previous := x.scores[game.Id]
+		message.Delta = &ScoreDelta{Home: current.Home - previous.Home, Away: current.Away - previous.Away}
+		if event.Type == EventMatchFinished {
+			delete(x.scores, game.Id)
+		} else {
+			x.scores[game.Id] = current
+		}
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		x.logger.Println("failed to encode hub message: ", err)
+		return
+	}
+
+	for client := range x.clients {
+		if client.wants(&game) {
+			x.enqueue(client, data)
+		}
+	}
+}
+
+// enqueue queues the data for the client, disconnecting it if its queue is full. The lock must be held by the caller.
+func (x *Hub) enqueue(client *HubClient, data []byte) {
+	if client.closed {
+		return
+	}
+	select {
+	case client.send <- data:
+	default:
+		x.logger.Println("hub client is too slow, disconnecting")
+		x.disconnect(client)
+	}
+}
+
+// disconnect removes the client from the hub and closes its send queue. The lock must be held by the caller.
+func (x *Hub) disconnect(client *HubClient) {
+	if client.closed {
+		return
+	}
+	client.closed = true
+	delete(x.clients, client)
+	close(client.send)
+}
+
+// disconnectAll disconnects all clients of the hub.
+func (x *Hub) disconnectAll() {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	for client := range x.clients {
+		x.disconnect(client)
+	}
+}
+
+// wants reports whether the client is subscribed to the game or one of its teams. The hub lock must be held by the caller.
+func (x *HubClient) wants(game *models.Game) bool {
+	if _, ok := x.matches[game.Id]; ok {
+		return true
+	}
+	if _, ok := x.teams[game.HomeTeam]; ok {
+		return true
+	}
+	_, ok := x.teams[game.AwayTeam]
+	return ok
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func receiveHubMessage(t *testing.T, client *HubClient) HubMessage {
+	var message HubMessage
+	select {
+	case data := <-client.Send:
+		assert.NoError(t, json.Unmarshal(data, &message))
+	case <-time.After(time.Second):
+		t.Fatal("no hub message received")
+	}
+	return message
+}
+
+func TestHub_Dispatch(t *testing.T) {
+	broker := NewEventBroker(DefaultEventHistory)
+	board := NewObservedBoard(NewScoreBoard(), broker)
+	hub := NewHub(broker)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	byMatch := hub.Connect()
+	byTeam := hub.Connect()
+	nothing := hub.Connect()
+	hub.SubscribeMatch(byMatch, 1)
+	hub.SubscribeTeam(byTeam, models.Poland)
+
+	spain, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	poland, err := board.StartGame("Poland", "USA")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(spain.Id, 2, 0)
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(poland.Id, 1, 1)
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(spain.Id, 2, 1)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		client *HubClient
+		want   []ScoreDelta
+	}{
+		{
+			name:   "Subscribed to match",
+			client: byMatch,
+			want:   []ScoreDelta{{0, 0}, {2, 0}, {0, 1}},
+		},
+		{
+			name:   "Subscribed to team",
+			client: byTeam,
+			want:   []ScoreDelta{{0, 0}, {1, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				message := receiveHubMessage(t, tt.client)
+				assert.Equal(t, want, *message.Delta)
+			}
+		})
+	}
+
+	hub.Disconnect(byMatch)
+	hub.Disconnect(byTeam)
+	hub.Disconnect(nothing)
+	_, ok := <-nothing.Send
+	assert.False(t, ok)
+}
+
+func TestHub_SlowClientIsDisconnected(t *testing.T) {
+	hub := NewHub(NewEventBroker(DefaultEventHistory))
+	slow := hub.Connect()
+	fast := hub.Connect()
+	hub.SubscribeMatch(slow, 1)
+	hub.SubscribeMatch(fast, 1)
+
+	game := models.Game{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil}
+	received := 0
+	for i := 0; i < clientQueueSize+1; i++ {
+		hub.dispatch(Event{Id: uint64(i + 1), Type: EventScoreUpdated, Game: game})
+		<-fast.Send
+		received++
+	}
+
+	assert.Equal(t, clientQueueSize+1, received)
+	queued := 0
+	for range slow.Send {
+		queued++
+	}
+	assert.Equal(t, clientQueueSize, queued)
+}
+
+func TestApp_WebSocket_Subscribe(t *testing.T) {
+	broker := NewEventBroker(DefaultEventHistory)
+	hub := NewHub(broker)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	app := NewApp(NewScoreBase(), NewObservedBoard(NewScoreBoard(), broker), WithEvents(broker), WithHub(hub))
+	app.InitRoutes()
+	server := httptest.NewServer(app.Server.Handler)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/ws", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	var reply HubMessage
+	assert.NoError(t, conn.WriteJSON(wsClientMessage{Action: "subscribe", Team: "Norway"}))
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, "error", reply.Type)
+
+	assert.NoError(t, conn.WriteJSON(wsClientMessage{Action: "subscribe", Team: "Spain"}))
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, "subscribed", reply.Type)
+	assert.Equal(t, models.Spain, reply.Team)
+
+	game, err := app.board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = app.board.UpdateGame(game.Id, 0, 1)
+	assert.NoError(t, err)
+
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, string(EventMatchStarted), reply.Type)
+	assert.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, string(EventScoreUpdated), reply.Type)
+	assert.Equal(t, ScoreDelta{Home: 0, Away: 1}, *reply.Delta)
+}
@@ -0,0 +1,85 @@
+package i18n
+
+// englishMessages is the English catalog of UI strings.
+var englishMessages = map[string]string{
+	"matches.title":       "Matches",
+	"matches.start":       "Selection of countries for the match",
+	"matches.home_team":   "First country:",
+	"matches.away_team":   "Second country:",
+	"matches.start_game":  "Start a match",
+	"matches.schedule":    "Schedule a match",
+	"matches.active":      "Active matches",
+	"matches.completed":   "Completed matches",
+	"matches.older":       "Older matches",
+	"matches.winner":      "winner: %s",
+	"matches.regulation":  "regulation %d : %d",
+	"matches.extra_time":  "extra time %d : %d",
+	"matches.penalties":   "penalties %d : %d",
+	"matches.shootout":    "Update the shootout",
+	"matches.transition":  "Change the status",
+	"matches.operator":    "Operator",
+	"matches.reason":      "Reason",
+	"matches.update":      "Update the result",
+	"matches.undo":        "Undo the last correction",
+	"matches.minute":      "Minute",
+	"matches.player":      "Player",
+	"matches.record_goal": "Record a goal",
+	"matches.finish":      "Finish match",
+	"matches.details":     "Match page",
+
+	"match.title":      "%s - %s",
+	"match.started":    "Started: %s",
+	"match.elapsed":    "Playing for %s",
+	"match.venue":      "Venue: %s",
+	"match.timeline":   "Timeline",
+	"match.no_goals":   "No goals yet",
+	"match.history":    "Score history",
+	"match.no_history": "No score changes",
+	"match.undo":       "undo",
+
+	"auth.login":     "Log in",
+	"auth.logout":    "Log out",
+	"auth.signed_in": "Signed in as %s",
+	"auth.name":      "User name",
+	"auth.password":  "Password",
+	"auth.failed":    "Invalid user name or password",
+
+	"fixtures.title":    "Upcoming fixtures",
+	"fixtures.venue":    "Venue",
+	"fixtures.schedule": "Schedule a fixture",
+
+	"league.title":    "League table",
+	"league.rule":     "Points for a win, a draw and a loss: %s",
+	"league.position": "#",
+	"league.team":     "Team",
+	"league.played":   "P",
+	"league.won":      "W",
+	"league.drawn":    "D",
+	"league.lost":     "L",
+	"league.for":      "GF",
+	"league.against":  "GA",
+	"league.diff":     "GD",
+	"league.points":   "Pts",
+
+	"timeline.own_goal": "own goal",
+	"timeline.penalty":  "penalty",
+
+	"goal.goal":     "Goal",
+	"goal.own_goal": "Own goal",
+	"goal.penalty":  "Penalty",
+
+	"status.scheduled":  "scheduled",
+	"status.live":       "live",
+	"status.half_time":  "half time",
+	"status.extra_time": "extra time",
+	"status.penalties":  "penalties",
+	"status.finished":   "finished",
+	"status.abandoned":  "abandoned",
+
+	"transition.kickoff":    "kickoff",
+	"transition.half_time":  "half time",
+	"transition.resume":     "resume",
+	"transition.extra_time": "extra time",
+	"transition.shootout":   "shootout",
+	"transition.abandon":    "abandon",
+}
@@ -0,0 +1,158 @@
+// Package i18n localizes the HTML UI: message catalogs of UI strings and team names, the negotiation of the locale
+// of a request and the formatting of numbers and dates. English and Russian are supported, English is the default
+// and the fallback of messages missing from a catalog.
+package i18n
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/number"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+const (
+	// QueryParam is the query parameter selecting the locale of a page, e.g. ?lang=ru.
+	QueryParam = "lang"
+	// CookieName is the name of the cookie remembering the locale selected by the query parameter.
+	CookieName = "lang"
+)
+
+// builder keeps the messages of all locales.
+var builder = catalog.NewBuilder(catalog.Fallback(language.English))
+
+// Locale represents a supported language with its messages, team names and date format.
+// Name is the name of the language in the language itself, for offering it to users.
+type Locale struct {
+	Tag            language.Tag
+	Name           string
+	dateTimeLayout string
+	teams          map[models.Countries]string
+	printer        *message.Printer
+}
+
+var (
+	// English is the default locale.
+	English = newLocale(language.English, "English", "2006-01-02 15:04", englishMessages, nil)
+	// Russian is the Russian locale.
+	Russian = newLocale(language.Russian, "Русский", "02.01.2006 15:04", russianMessages, russianTeams)
+)
+
+// locales are the supported locales, the default first.
+var locales = []*Locale{English, Russian}
+
+// matcher matches requested languages against the supported locales.
+var matcher = language.NewMatcher([]language.Tag{English.Tag, Russian.Tag})
+
+// newLocale adds the messages of the locale to the catalog and returns the locale.
+func newLocale(tag language.Tag, name, dateTimeLayout string, messages map[string]string, teams map[models.Countries]string) *Locale {
+	for key, msg := range messages {
+		if err := builder.SetString(tag, key, msg); err != nil {
+			panic(err)
+		}
+	}
+	return &Locale{
+		Tag:            tag,
+		Name:           name,
+		dateTimeLayout: dateTimeLayout,
+		teams:          teams,
+		printer:        message.NewPrinter(tag, message.Catalog(builder)),
+	}
+}
+
+// Locales returns the supported locales, the default first.
+func Locales() []*Locale {
+	return locales
+}
+
+// Lookup returns the supported locale best matching the language tag, e.g. "ru" or "ru-RU",
+// and reports whether the tag matches a supported locale.
+func Lookup(tag string) (*Locale, bool) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return nil, false
+	}
+	_, index, confidence := matcher.Match(parsed)
+	if confidence == language.No {
+		return nil, false
+	}
+	return locales[index], true
+}
+
+// FromRequest returns the locale of the request: the one selected by the query parameter, by the cookie,
+// or the best match of the Accept-Language header, in that order. English is returned if none matches.
+func FromRequest(r *http.Request) *Locale {
+	if locale, ok := Lookup(r.URL.Query().Get(QueryParam)); ok {
+		return locale
+	}
+	if cookie, err := r.Cookie(CookieName); err == nil {
+		if locale, ok := Lookup(cookie.Value); ok {
+			return locale
+		}
+	}
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return English
+	}
+	return locales[index]
+}
+
+// Lang returns the language tag of the locale for the lang attribute of pages, e.g. "ru".
+func (x *Locale) Lang() string {
+	return x.Tag.String()
+}
+
+// Translate returns the message of the locale with the provided key formatted with the arguments,
+// numbers formatted for the locale. Missing messages fall back to English, then to the key itself.
+func (x *Locale) Translate(key string, args ...any) string {
+	return x.printer.Sprintf(key, args...)
+}
+
+// Team returns the name of the team in the locale: the one of the catalog, otherwise the display name
+// of the team in the registry in use, otherwise its id.
+func (x *Locale) Team(team models.Countries) string {
+	if name, ok := x.teams[team]; ok {
+		return name
+	}
+	if registered, ok := models.Teams().Team(team); ok {
+		return registered.Name
+	}
+	return string(team)
+}
+
+// Number returns the number formatted for the locale, e.g. 1,234 in English and 1 234, with a no-break space, in Russian.
+func (x *Locale) Number(value any) string {
+	return x.printer.Sprint(number.Decimal(value))
+}
+
+// DateTime returns the date and time formatted for the locale.
+func (x *Locale) DateTime(value time.Time) string {
+	return value.Format(x.dateTimeLayout)
+}
+
+// Funcs returns the template functions localizing a page: t translates a message, team names a team,
+// number and datetime format values, and status, transition and goal name the values of these types.
+func (x *Locale) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"t":        x.Translate,
+		"team":     x.Team,
+		"number":   x.Number,
+		"datetime": x.DateTime,
+		"status": func(status models.Status) string {
+			return x.Translate("status." + string(status))
+		},
+		"transition": func(transition models.Transition) string {
+			return x.Translate("transition." + string(transition))
+		},
+		"goal": func(goalType models.GoalType) string {
+			return x.Translate("goal." + string(goalType))
+		},
+	}
+}
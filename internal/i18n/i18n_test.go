@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCatalogs(t *testing.T) {
+	for key := range englishMessages {
+		assert.Contains(t, russianMessages, key, "missing Russian message")
+	}
+	for key := range russianMessages {
+		assert.Contains(t, englishMessages, key, "missing English message")
+	}
+	for _, team := range models.BuiltinTeams() {
+		assert.Contains(t, russianTeams, team.Id, "missing Russian team name")
+	}
+	for _, status := range []models.Status{models.StatusScheduled, models.StatusLive, models.StatusHalfTime,
+		models.StatusExtraTime, models.StatusPenalties, models.StatusFinished, models.StatusAbandoned} {
+		assert.Contains(t, englishMessages, "status."+string(status))
+	}
+	for _, transition := range models.AllTransitions {
+		assert.Contains(t, englishMessages, "transition."+string(transition))
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		cookie         string
+		acceptLanguage string
+		want           *Locale
+	}{
+		{name: "Default", want: English},
+		{name: "Accept-Language", acceptLanguage: "ru-RU,ru;q=0.9,en;q=0.8", want: Russian},
+		{name: "Accept-Language preferring English", acceptLanguage: "en-GB,ru;q=0.5", want: English},
+		{name: "Unsupported Accept-Language", acceptLanguage: "de-DE", want: English},
+		{name: "Invalid Accept-Language", acceptLanguage: ";;;", want: English},
+		{name: "Cookie over Accept-Language", cookie: "ru", acceptLanguage: "en", want: Russian},
+		{name: "Query over cookie", query: "en", cookie: "ru", want: English},
+		{name: "Query with region", query: "ru-RU", want: Russian},
+		{name: "Unsupported query", query: "xx", acceptLanguage: "ru", want: Russian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?lang="+tt.query, nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: CookieName, Value: tt.cookie})
+			}
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			assert.Equal(t, tt.want, FromRequest(r))
+		})
+	}
+}
+
+func TestLocale(t *testing.T) {
+	assert.Equal(t, "en", English.Lang())
+	assert.Equal(t, "ru", Russian.Lang())
+
+	assert.Equal(t, "Текущие матчи", Russian.Translate("matches.active"))
+	assert.Equal(t, "winner: Brazil", English.Translate("matches.winner", "Brazil"))
+	assert.Equal(t, "unknown.key", Russian.Translate("unknown.key"))
+
+	assert.Equal(t, "Испания", Russian.Team(models.Spain))
+	assert.Equal(t, "United Kingdom", English.Team(models.UK))
+	assert.Equal(t, "Narnia", Russian.Team("Narnia"))
+
+	assert.Equal(t, "1,234,567", English.Number(1234567))
+	assert.Equal(t, "1\u00a0234\u00a0567", Russian.Number(1234567))
+	assert.Equal(t, "-3", Russian.Number(-3))
+
+	kickoff := time.Date(2026, 6, 11, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-06-11 20:00", English.DateTime(kickoff))
+	assert.Equal(t, "11.06.2026 20:00", Russian.DateTime(kickoff))
+}
@@ -0,0 +1,111 @@
+package i18n
+
+import "github.com/Marian2701/CodingExercise/internal/models"
+
+// russianMessages is the Russian catalog of UI strings.
+var russianMessages = map[string]string{
+	"matches.title":       "Матчи",
+	"matches.start":       "Выбор команд для матча",
+	"matches.home_team":   "Первая команда:",
+	"matches.away_team":   "Вторая команда:",
+	"matches.start_game":  "Начать матч",
+	"matches.schedule":    "Запланировать матч",
+	"matches.active":      "Текущие матчи",
+	"matches.completed":   "Завершённые матчи",
+	"matches.older":       "Более ранние матчи",
+	"matches.winner":      "победитель: %s",
+	"matches.regulation":  "основное время %d : %d",
+	"matches.extra_time":  "дополнительное время %d : %d",
+	"matches.penalties":   "пенальти %d : %d",
+	"matches.shootout":    "Обновить серию пенальти",
+	"matches.transition":  "Изменить статус",
+	"matches.operator":    "Оператор",
+	"matches.reason":      "Причина",
+	"matches.update":      "Обновить счёт",
+	"matches.undo":        "Отменить последнее исправление",
+	"matches.minute":      "Минута",
+	"matches.player":      "Игрок",
+	"matches.record_goal": "Записать гол",
+	"matches.finish":      "Завершить матч",
+	"matches.details":     "Страница матча",
+
+	"match.title":      "%s - %s",
+	"match.started":    "Начало: %s",
+	"match.elapsed":    "Идёт %s",
+	"match.venue":      "Стадион: %s",
+	"match.timeline":   "Хронология",
+	"match.no_goals":   "Голов пока нет",
+	"match.history":    "История счёта",
+	"match.no_history": "Счёт не менялся",
+	"match.undo":       "отмена",
+
+	"auth.login":     "Войти",
+	"auth.logout":    "Выйти",
+	"auth.signed_in": "Вы вошли как %s",
+	"auth.name":      "Имя пользователя",
+	"auth.password":  "Пароль",
+	"auth.failed":    "Неверное имя пользователя или пароль",
+
+	"fixtures.title":    "Ближайшие матчи",
+	"fixtures.venue":    "Стадион",
+	"fixtures.schedule": "Добавить в расписание",
+
+	"league.title":    "Турнирная таблица",
+	"league.rule":     "Очки за победу, ничью и поражение: %s",
+	"league.position": "№",
+	"league.team":     "Команда",
+	"league.played":   "И",
+	"league.won":      "В",
+	"league.drawn":    "Н",
+	"league.lost":     "П",
+	"league.for":      "ЗМ",
+	"league.against":  "ПМ",
+	"league.diff":     "РМ",
+	"league.points":   "О",
+
+	"timeline.own_goal": "автогол",
+	"timeline.penalty":  "пенальти",
+
+	"goal.goal":     "Гол",
+	"goal.own_goal": "Автогол",
+	"goal.penalty":  "Пенальти",
+
+	"status.scheduled":  "запланирован",
+	"status.live":       "идёт",
+	"status.half_time":  "перерыв",
+	"status.extra_time": "дополнительное время",
+	"status.penalties":  "серия пенальти",
+	"status.finished":   "завершён",
+	"status.abandoned":  "прерван",
+
+	"transition.kickoff":    "начать",
+	"transition.half_time":  "перерыв",
+	"transition.resume":     "продолжить",
+	"transition.extra_time": "дополнительное время",
+	"transition.shootout":   "серия пенальти",
+	"transition.abandon":    "прервать",
+}
+
+// russianTeams is the Russian catalog of the names of the built-in teams.
+var russianTeams = map[models.Countries]string{
+	models.Argentina:   "Аргентина",
+	models.Australia:   "Австралия",
+	models.Brazil:      "Бразилия",
+	models.Canada:      "Канада",
+	models.China:       "Китай",
+	models.Denmark:     "Дания",
+	models.Egypt:       "Египет",
+	models.France:      "Франция",
+	models.Germany:     "Германия",
+	models.India:       "Индия",
+	models.Indonesia:   "Индонезия",
+	models.Italy:       "Италия",
+	models.Japan:       "Япония",
+	models.Morocco:     "Марокко",
+	models.Nigeria:     "Нигерия",
+	models.Poland:      "Польша",
+	models.SouthAfrica: "ЮАР",
+	models.Spain:       "Испания",
+	models.UK:          "Великобритания",
+	models.USA:         "США",
+}
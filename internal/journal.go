@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// journalLogSuffix is the file name suffix of the append-only log of a journal.
+	journalLogSuffix = ".log"
+	// journalSnapshotSuffix is the file name suffix of the snapshot of a journal.
+	journalSnapshotSuffix = ".snapshot"
+	// journalTempSuffix is the file name suffix of a snapshot that is being written.
+	journalTempSuffix = ".tmp"
+)
+
+// journalEntry defines a single record of the log, or the content of the snapshot.
+// Seq grows with every appended record; a snapshot stores the Seq of the last record it contains,
+// so records that were already compacted into the snapshot are skipped on replay.
+type journalEntry struct {
+	Seq  uint64          `json:"seq"`
+	Data json.RawMessage `json:"data"`
+}
+
+// journal is an append-only log of JSON records, each one fsync'd to disk before append returns,
+// together with a snapshot file the log is periodically compacted into.
+// It is used by the file-backed implementations of ScoreBaseStoring and GameBoard.
+type journal struct {
+	lock     sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	seq      uint64
+	appended int
+}
+
+// openJournal opens the journal with the provided name in dir, creating dir if needed.
+// The content of the snapshot is decoded into snapshot, then apply is called for every record of the log
+// that is newer than the snapshot. A partially written record at the end of the log, left by a crash
+// in the middle of an append, is discarded.
+func openJournal(dir, name string, snapshot any, apply func(data json.RawMessage) error) (*journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	x := &journal{path: filepath.Join(dir, name)}
+
+	if err := x.readSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(x.path+journalLogSuffix, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal log: %w", err)
+	}
+
+	valid, err := x.replay(file, apply)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if err := file.Truncate(valid); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("truncate journal log: %w", err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("seek journal log: %w", err)
+	}
+
+	x.file = file
+	x.size = valid
+	return x, nil
+}
+
+// readSnapshot decodes the snapshot file into snapshot, leaving it untouched if there is no snapshot yet.
+func (x *journal) readSnapshot(snapshot any) error {
+	data, err := os.ReadFile(x.path + journalSnapshotSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read journal snapshot: %w", err)
+	}
+
+	var entry journalEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("decode journal snapshot: %w", err)
+	}
+	if err := json.Unmarshal(entry.Data, snapshot); err != nil {
+		return fmt.Errorf("decode journal snapshot: %w", err)
+	}
+	x.seq = entry.Seq
+	return nil
+}
+
+// replay calls apply for every record of the log newer than the snapshot and returns the size
+// of the valid part of the log. Only the last record may be invalid, anything else is reported as corruption.
+func (x *journal) replay(file *os.File, apply func(data json.RawMessage) error) (int64, error) {
+	reader := bufio.NewReader(file)
+	var valid int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A record without the trailing newline was not completely written.
+			return valid, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read journal log: %w", err)
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+				return valid, nil
+			}
+			return 0, fmt.Errorf("decode journal log at offset %d: %w", valid, err)
+		}
+		valid += int64(len(line))
+
+		if entry.Seq <= x.seq {
+			continue
+		}
+		if err := apply(entry.Data); err != nil {
+			return 0, fmt.Errorf("apply journal record %d: %w", entry.Seq, err)
+		}
+		x.seq = entry.Seq
+		x.appended++
+	}
+}
+
+// append writes the record to the end of the log and waits until it is flushed to disk.
+func (x *journal) append(record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	line, err := json.Marshal(journalEntry{Seq: x.seq + 1, Data: data})
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := x.file.Write(line); err != nil {
+		x.rollback()
+		return fmt.Errorf("write journal log: %w", err)
+	}
+	if err := x.file.Sync(); err != nil {
+		x.rollback()
+		return fmt.Errorf("sync journal log: %w", err)
+	}
+
+	x.size += int64(len(line))
+	x.seq++
+	x.appended++
+	return nil
+}
+
+// rollback drops a record that failed to be appended, so it is not replayed later
+// and the following records are not appended after garbage. The lock must be held by the caller.
+func (x *journal) rollback() {
+	_ = x.file.Truncate(x.size)
+	_, _ = x.file.Seek(x.size, io.SeekStart)
+}
+
+// pending returns the number of records appended to the log since the last compaction.
+func (x *journal) pending() int {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.appended
+}
+
+// compact replaces the snapshot with the provided state and empties the log.
+// The state has to include every record appended so far, so the caller must not append concurrently.
+// The snapshot is written to a temporary file and renamed, so a crash leaves either the old or the new snapshot;
+// in both cases the records of the log are skipped or applied according to the sequence stored in the snapshot.
+func (x *journal) compact(state any) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode journal snapshot: %w", err)
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	content, err := json.Marshal(journalEntry{Seq: x.seq, Data: data})
+	if err != nil {
+		return fmt.Errorf("encode journal snapshot: %w", err)
+	}
+	if err := writeFileSync(x.path+journalSnapshotSuffix+journalTempSuffix, content); err != nil {
+		return err
+	}
+	if err := os.Rename(x.path+journalSnapshotSuffix+journalTempSuffix, x.path+journalSnapshotSuffix); err != nil {
+		return fmt.Errorf("replace journal snapshot: %w", err)
+	}
+	if err := syncDir(filepath.Dir(x.path)); err != nil {
+		return err
+	}
+
+	if err := x.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate journal log: %w", err)
+	}
+	if _, err := x.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek journal log: %w", err)
+	}
+	if err := x.file.Sync(); err != nil {
+		return fmt.Errorf("sync journal log: %w", err)
+	}
+
+	x.size = 0
+	x.appended = 0
+	return nil
+}
+
+// close closes the log file of the journal.
+func (x *journal) close() error {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.file.Close()
+}
+
+// writeFileSync writes data to the file at path and flushes it to disk.
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("sync %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// syncDir flushes the directory entry changes, such as a rename, to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open data dir: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync data dir: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package league
+
+import "errors"
+
+var (
+	ErrInvalidPointsRule = errors.New("invalid points rule")
+	ErrUnknownTieBreaker = errors.New("unknown tie-breaker")
+)
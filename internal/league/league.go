@@ -0,0 +1,220 @@
+// Package league computes a league table from finished games. Unlike a tournament, a league has no groups
+// or knockout stage: every team that finished a game is in the table. The table is kept up to date incrementally,
+// every recorded game only changes the rows of its two teams.
+package league
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// PointsRule defines the points a team gets for a win, a draw and a loss.
+type PointsRule struct {
+	Win  uint `json:"win"`
+	Draw uint `json:"draw"`
+	Loss uint `json:"loss"`
+}
+
+var (
+	// ThreePointsForWin is the points rule used by most leagues today.
+	ThreePointsForWin = PointsRule{Win: 3, Draw: 1, Loss: 0}
+	// TwoPointsForWin is the points rule used before three points for a win were introduced.
+	TwoPointsForWin = PointsRule{Win: 2, Draw: 1, Loss: 0}
+)
+
+// ParsePointsRule parses a points rule written as the points for a win, a draw and a loss separated by dashes,
+// e.g. "3-1-0". ErrInvalidPointsRule is returned for anything else.
+func ParsePointsRule(value string) (PointsRule, error) {
+	var rule PointsRule
+	var rest string
+	n, _ := fmt.Sscanf(value, "%d-%d-%d%s", &rule.Win, &rule.Draw, &rule.Loss, &rest)
+	if n != 3 {
+		return PointsRule{}, ErrInvalidPointsRule
+	}
+	return rule, nil
+}
+
+// String returns the points rule in the format accepted by ParsePointsRule.
+func (x PointsRule) String() string {
+	return fmt.Sprintf("%d-%d-%d", x.Win, x.Draw, x.Loss)
+}
+
+// TieBreaker defines a criterion ranking teams level on points.
+type TieBreaker string
+
+const (
+	// TieBreakerGoalDifference ranks the team with the higher goal difference first.
+	TieBreakerGoalDifference TieBreaker = "goal_difference"
+	// TieBreakerGoalsFor ranks the team that scored more goals first.
+	TieBreakerGoalsFor TieBreaker = "goals_for"
+	// TieBreakerWins ranks the team with more wins first.
+	TieBreakerWins TieBreaker = "wins"
+	// TieBreakerGoalsAgainst ranks the team that conceded fewer goals first.
+	TieBreakerGoalsAgainst TieBreaker = "goals_against"
+)
+
+// DefaultTieBreakers are the tie-breakers applied by most leagues, goal difference and then goals scored.
+var DefaultTieBreakers = []TieBreaker{TieBreakerGoalDifference, TieBreakerGoalsFor}
+
+// comparisons maps every tie-breaker to the comparison of two rows, the better row first.
+var comparisons = map[TieBreaker]func(a, b Row) int{
+	TieBreakerGoalDifference: func(a, b Row) int { return cmp.Compare(b.GoalDifference, a.GoalDifference) },
+	TieBreakerGoalsFor:       func(a, b Row) int { return cmp.Compare(b.GoalsFor, a.GoalsFor) },
+	TieBreakerWins:           func(a, b Row) int { return cmp.Compare(b.Won, a.Won) },
+	TieBreakerGoalsAgainst:   func(a, b Row) int { return cmp.Compare(a.GoalsAgainst, b.GoalsAgainst) },
+}
+
+// ParseTieBreakers parses a comma separated list of tie-breakers, e.g. "goal_difference,goals_for".
+// An empty list means no tie-breakers. ErrUnknownTieBreaker is returned for an unknown tie-breaker.
+func ParseTieBreakers(value string) ([]TieBreaker, error) {
+	var result []TieBreaker
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := comparisons[TieBreaker(name)]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTieBreaker, name)
+		}
+		result = append(result, TieBreaker(name))
+	}
+	return result, nil
+}
+
+// Row represents the record of a team in the league table.
+type Row struct {
+	Position       int              `json:"position"`
+	Team           models.Countries `json:"team"`
+	Played         uint             `json:"played"`
+	Won            uint             `json:"won"`
+	Drawn          uint             `json:"drawn"`
+	Lost           uint             `json:"lost"`
+	GoalsFor       uint             `json:"goals_for"`
+	GoalsAgainst   uint             `json:"goals_against"`
+	GoalDifference int              `json:"goal_difference"`
+	Points         uint             `json:"points"`
+}
+
+// Table represents a league table kept up to date by recording finished games one by one.
+// Teams are ranked by points, then by the tie-breakers in the configured order, and finally by name.
+// Table is safe for concurrent use.
+type Table struct {
+	rule        PointsRule
+	tieBreakers []TieBreaker
+	rows        map[models.Countries]*Row
+	recorded    map[uint32]bool
+	lock        sync.RWMutex
+}
+
+// New returns a new empty table awarding points by the rule and ranking teams level on points by the tie-breakers.
+// ErrUnknownTieBreaker is returned for an unknown tie-breaker.
+func New(rule PointsRule, tieBreakers ...TieBreaker) (*Table, error) {
+	for _, tieBreaker := range tieBreakers {
+		if _, ok := comparisons[tieBreaker]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTieBreaker, tieBreaker)
+		}
+	}
+	return &Table{
+		rule:        rule,
+		tieBreakers: tieBreakers,
+		rows:        make(map[models.Countries]*Row),
+		recorded:    make(map[uint32]bool),
+	}, nil
+}
+
+// Rule returns the points rule of the table.
+func (x *Table) Rule() PointsRule {
+	return x.rule
+}
+
+// Record adds the result of the game to the rows of its teams and reports whether the table was changed.
+// Abandoned games and games recorded before, by id, are ignored, so recording the same games again is harmless.
+// The penalty shootout does not count, a game that was level after extra time is a draw.
+func (x *Table) Record(game *models.Game) bool {
+	if game.Status == models.StatusAbandoned {
+		return false
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if x.recorded[game.Id] {
+		return false
+	}
+	x.recorded[game.Id] = true
+	x.row(game.HomeTeam).record(game.HomeScore, game.AwayScore, x.rule)
+	x.row(game.AwayTeam).record(game.AwayScore, game.HomeScore, x.rule)
+	return true
+}
+
+// Standings returns a copy of the rows of all teams in the order of the table, with their positions set.
+// Teams level on points and all tie-breakers share the position, and are listed by name.
+func (x *Table) Standings() []Row {
+	x.lock.RLock()
+	result := make([]Row, 0, len(x.rows))
+	for _, row := range x.rows {
+		result = append(result, *row)
+	}
+	x.lock.RUnlock()
+
+	slices.SortFunc(result, func(a, b Row) int {
+		if c := x.compare(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Team, b.Team)
+	})
+	for i := range result {
+		if i > 0 && x.compare(result[i-1], result[i]) == 0 {
+			result[i].Position = result[i-1].Position
+		} else {
+			result[i].Position = i + 1
+		}
+	}
+	return result
+}
+
+// compare orders rows by points and then by the tie-breakers of the table, the better row first.
+func (x *Table) compare(a, b Row) int {
+	if c := cmp.Compare(b.Points, a.Points); c != 0 {
+		return c
+	}
+	for _, tieBreaker := range x.tieBreakers {
+		if c := comparisons[tieBreaker](a, b); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// row returns the row of the team, adding an empty one for a team without games. The lock must be held by the caller.
+func (x *Table) row(team models.Countries) *Row {
+	row, ok := x.rows[team]
+	if !ok {
+		row = &Row{Team: team}
+		x.rows[team] = row
+	}
+	return row
+}
+
+// record adds the result of a game to the row.
+func (x *Row) record(scored, conceded uint, rule PointsRule) {
+	x.Played++
+	x.GoalsFor += scored
+	x.GoalsAgainst += conceded
+	x.GoalDifference = int(x.GoalsFor) - int(x.GoalsAgainst)
+	switch {
+	case scored > conceded:
+		x.Won++
+		x.Points += rule.Win
+	case scored == conceded:
+		x.Drawn++
+		x.Points += rule.Draw
+	default:
+		x.Lost++
+		x.Points += rule.Loss
+	}
+}
@@ -0,0 +1,125 @@
+package league
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePointsRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    PointsRule
+		wantErr error
+	}{
+		{name: "Three points for a win", value: "3-1-0", want: ThreePointsForWin},
+		{name: "Two points for a win", value: "2-1-0", want: TwoPointsForWin},
+		{name: "Missing loss", value: "3-1", wantErr: ErrInvalidPointsRule},
+		{name: "Trailing text", value: "3-1-0x", wantErr: ErrInvalidPointsRule},
+		{name: "Negative points", value: "3--1-0", wantErr: ErrInvalidPointsRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParsePointsRule(tt.value)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, rule)
+		})
+	}
+}
+
+func TestParseTieBreakers(t *testing.T) {
+	tieBreakers, err := ParseTieBreakers(" goal_difference, wins,")
+	assert.NoError(t, err)
+	assert.Equal(t, []TieBreaker{TieBreakerGoalDifference, TieBreakerWins}, tieBreakers)
+
+	_, err = ParseTieBreakers("goal_difference,fair_play")
+	assert.ErrorIs(t, err, ErrUnknownTieBreaker)
+	_, err = New(ThreePointsForWin, "fair_play")
+	assert.ErrorIs(t, err, ErrUnknownTieBreaker)
+}
+
+func TestTable_Standings(t *testing.T) {
+	abandoned := &models.Game{Id: 5, HomeTeam: models.Japan, HomeScore: 0, AwayTeam: models.Brazil, AwayScore: 9, Status: models.StatusAbandoned}
+	games := []*models.Game{
+		// Spain wins once and loses once, Brazil draws twice.
+		&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 4, AwayTeam: models.Germany, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 2, HomeTeam: models.Germany, HomeScore: 1, AwayTeam: models.Spain, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 3, HomeTeam: models.Brazil, HomeScore: 1, AwayTeam: models.Germany, AwayScore: 1, Status: models.StatusFinished},
+		&models.Game{Id: 4, HomeTeam: models.Brazil, HomeScore: 2, AwayTeam: models.Japan, AwayScore: 2, Status: models.StatusFinished},
+		abandoned,
+	}
+
+	tests := []struct {
+		name        string
+		rule        PointsRule
+		tieBreakers []TieBreaker
+		wantTeams   []models.Countries
+		wantPoints  []uint
+		wantPlaces  []int
+	}{
+		{
+			name:        "Three points for a win",
+			rule:        ThreePointsForWin,
+			tieBreakers: DefaultTieBreakers,
+			wantTeams:   []models.Countries{models.Spain, models.Germany, models.Brazil, models.Japan},
+			wantPoints:  []uint{3, 4, 2, 1},
+			wantPlaces:  []int{2, 1, 3, 4},
+		},
+		{
+			name:        "Two points for a win ranked by goal difference",
+			rule:        TwoPointsForWin,
+			tieBreakers: DefaultTieBreakers,
+			wantTeams:   []models.Countries{models.Spain, models.Germany, models.Brazil, models.Japan},
+			wantPoints:  []uint{2, 3, 2, 1},
+			wantPlaces:  []int{2, 1, 3, 4},
+		},
+		{
+			name:        "Two points for a win without tie-breakers",
+			rule:        TwoPointsForWin,
+			tieBreakers: nil,
+			wantTeams:   []models.Countries{models.Spain, models.Germany, models.Brazil, models.Japan},
+			wantPoints:  []uint{2, 3, 2, 1},
+			wantPlaces:  []int{2, 1, 2, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := New(tt.rule, tt.tieBreakers...)
+			assert.NoError(t, err)
+			for _, game := range games {
+				table.Record(game)
+			}
+			rows := table.Standings()
+			assert.Equal(t, len(tt.wantTeams), len(rows))
+			for i, team := range tt.wantTeams {
+				for _, row := range rows {
+					if row.Team == team {
+						assert.Equal(t, tt.wantPoints[i], row.Points, team)
+						assert.Equal(t, tt.wantPlaces[i], row.Position, team)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTable_Record(t *testing.T) {
+	table, err := New(ThreePointsForWin, DefaultTieBreakers...)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(table.Standings()))
+
+	game := &models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 3, AwayTeam: models.Brazil, AwayScore: 1, Status: models.StatusFinished}
+	assert.Equal(t, true, table.Record(game))
+	// Recording a game twice, e.g. when an interrupted finish is retried, must not count it twice.
+	assert.Equal(t, false, table.Record(game))
+
+	shootout := &models.Game{Id: 2, HomeTeam: models.Brazil, HomeScore: 1, AwayTeam: models.Spain, AwayScore: 1, Status: models.StatusFinished, PenaltyScore: &models.Score{Home: 5, Away: 4}}
+	assert.Equal(t, true, table.Record(shootout))
+
+	rows := table.Standings()
+	assert.Equal(t, Row{Position: 1, Team: models.Spain, Played: 2, Won: 1, Drawn: 1, GoalsFor: 4, GoalsAgainst: 2, GoalDifference: 2, Points: 4}, rows[0])
+	assert.Equal(t, Row{Position: 2, Team: models.Brazil, Played: 2, Drawn: 1, Lost: 1, GoalsFor: 2, GoalsAgainst: 4, GoalDifference: -2, Points: 1}, rows[1])
+}
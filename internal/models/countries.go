@@ -26,15 +26,14 @@ const (
 	USA         Countries = "USA"
 )
 
+// GetCountryFromString returns the id of the active team of the registry in use matching the name,
+// NotACountry if there is no such team. Use ResolveTeam to get the teams with similar names as well.
 func GetCountryFromString(countryName string) Countries {
-	for _, country := range AllCountries {
-		if string(country) == countryName {
-			return country
-		}
-	}
-	return NotACountry
+	team, _ := ResolveTeam(countryName)
+	return team
 }
 
+// AllCountries are the built-in teams, see BuiltinTeams.
 var AllCountries = []Countries{
 	Argentina,
 	Australia,
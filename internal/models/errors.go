@@ -5,4 +5,24 @@ import "errors"
 var (
 	ErrGameNotFound   = errors.New("game not found")
 	ErrInvalidCountry = errors.New("invalid country")
+	ErrInvalidCursor  = errors.New("invalid cursor")
+	ErrInvalidGoal    = errors.New("invalid goal")
+	ErrNothingToUndo  = errors.New("nothing to undo")
+	ErrScoreChanged   = errors.New("score changed since the last correction")
+
+	ErrUnknownTransition     = errors.New("unknown transition")
+	ErrInvalidTransition     = errors.New("transition not allowed in the current status")
+	ErrScoreUpdateNotAllowed = errors.New("score cannot be changed in the current status")
+	ErrShootoutUndecided     = errors.New("penalty shootout is undecided")
+
+	ErrSameTeam           = errors.New("home and away teams must differ")
+	ErrTeamAlreadyPlaying = errors.New("team is already playing")
+
+	ErrInvalidTeam   = errors.New("invalid team")
+	ErrDuplicateTeam = errors.New("team already exists")
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrTeamScheduled = errors.New("team has upcoming fixtures")
+
+	ErrFixtureNotFound = errors.New("fixture not found")
+	ErrInvalidKickoff  = errors.New("invalid kickoff time")
 )
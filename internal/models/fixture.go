@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Fixture represents a game scheduled in advance with the teams, the kickoff time and the venue.
+// GameId is the id of the game the fixture was promoted to at kickoff, zero while the fixture is upcoming.
+// Promoting is set once its game is being started at kickoff and cleared when the fixture is promoted.
+type Fixture struct {
+	Id        uint32    `json:"id"`
+	HomeTeam  Countries `json:"home_team"`
+	AwayTeam  Countries `json:"away_team"`
+	KickoffAt time.Time `json:"kickoff_at"`
+	Venue     string    `json:"venue"`
+	GameId    uint32    `json:"game_id,omitempty"`
+	Promoting bool      `json:"promoting,omitempty"`
+}
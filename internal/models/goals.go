@@ -0,0 +1,71 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// GoalType defines how a goal was scored.
+type GoalType string
+
+const (
+	// GoalRegular is a goal scored from open play or a set piece.
+	GoalRegular GoalType = "goal"
+	// GoalOwn is a goal a player scored into their own net, it counts for the opposing team.
+	GoalOwn GoalType = "own_goal"
+	// GoalPenalty is a goal scored from a penalty kick during the match.
+	GoalPenalty GoalType = "penalty"
+)
+
+// MaxGoalMinute is the latest minute a goal can be recorded at, covering extra time together with stoppage time.
+const MaxGoalMinute = 150
+
+// Goal represents a single goal of a game. Team is the team the goal counts for,
+// so for GoalOwn the Player belongs to the other team.
+type Goal struct {
+	Minute uint      `json:"minute"`
+	Team   Countries `json:"team"`
+	Player string    `json:"player"`
+	Type   GoalType  `json:"type"`
+}
+
+// AddGoal validates the goal against the game and its status, inserts it into the timeline ordered by minute,
+// after the goals of the same minute, and increments the score of the team the goal counts for,
+// together with the extra-time score during extra time.
+// The timeline is copied instead of being appended to in place, so games sharing it with the receiver are not affected.
+func (x *Game) AddGoal(goal Goal) error {
+	if !x.Status.AcceptsGoals() {
+		return ErrScoreUpdateNotAllowed
+	}
+	if goal.Minute < 1 || goal.Minute > MaxGoalMinute || strings.TrimSpace(goal.Player) == "" {
+		return ErrInvalidGoal
+	}
+	switch goal.Type {
+	case GoalRegular, GoalOwn, GoalPenalty:
+	default:
+		return ErrInvalidGoal
+	}
+	var extraTime Score
+	if x.ExtraTimeScore != nil {
+		extraTime = *x.ExtraTimeScore
+	}
+	switch goal.Team {
+	case x.HomeTeam:
+		x.HomeScore++
+		extraTime.Home++
+	case x.AwayTeam:
+		x.AwayScore++
+		extraTime.Away++
+	default:
+		return ErrInvalidGoal
+	}
+	if x.Status == StatusExtraTime {
+		x.ExtraTimeScore = &extraTime
+	}
+
+	i, _ := slices.BinarySearchFunc(x.Goals, goal.Minute+1, func(g Goal, minute uint) int {
+		return int(g.Minute) - int(minute)
+	})
+	x.Goals = slices.Insert(slices.Clip(x.Goals), i, goal)
+	return nil
+}
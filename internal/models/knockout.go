@@ -0,0 +1,82 @@
+package models
+
+import "encoding/json"
+
+// Score represents the goals of the home and away teams in a part of a game.
+type Score struct {
+	Home uint `json:"home"`
+	Away uint `json:"away"`
+}
+
+// SetScore sets the home and away scores of the game, e.g. to correct them, if its status accepts score updates.
+// After regulation time the correction is attributed to extra time, as far as the extra-time score allows;
+// a correction below the regulation score corrects the regulation score as well. During the penalty shootout
+// the score can only be corrected to another draw, since only a drawn game goes to penalties.
+// The parts of the score are replaced instead of being changed in place, so games sharing them are not affected.
+func (x *Game) SetScore(homeScore, awayScore uint) error {
+	if !x.Status.AcceptsScoreUpdates() || (x.Status == StatusPenalties && homeScore != awayScore) {
+		return ErrScoreUpdateNotAllowed
+	}
+	x.SetHomeScore(homeScore).SetAwayScore(awayScore)
+	if x.RegulationScore == nil {
+		return nil
+	}
+	if x.ExtraTimeScore == nil {
+		x.RegulationScore = &Score{Home: homeScore, Away: awayScore}
+		return nil
+	}
+	regulation := Score{Home: min(x.RegulationScore.Home, homeScore), Away: min(x.RegulationScore.Away, awayScore)}
+	x.RegulationScore = &regulation
+	x.ExtraTimeScore = &Score{Home: homeScore - regulation.Home, Away: awayScore - regulation.Away}
+	return nil
+}
+
+// SetPenaltyScore sets the score of the penalty shootout of the game. The game has to be in StatusPenalties.
+// Shootout goals are not included in HomeScore and AwayScore.
+func (x *Game) SetPenaltyScore(homePenalties, awayPenalties uint) error {
+	if x.Status != StatusPenalties {
+		return ErrScoreUpdateNotAllowed
+	}
+	x.PenaltyScore = &Score{Home: homePenalties, Away: awayPenalties}
+	return nil
+}
+
+// Winner returns the team that won the finished game, taking the penalty shootout into account,
+// or NotACountry if the game is not finished or ended in a draw.
+func (x *Game) Winner() Countries {
+	if x.Status != StatusFinished {
+		return NotACountry
+	}
+	home, away := x.HomeScore, x.AwayScore
+	if home == away && x.PenaltyScore != nil {
+		home, away = x.PenaltyScore.Home, x.PenaltyScore.Away
+	}
+	switch {
+	case home > away:
+		return x.HomeTeam
+	case away > home:
+		return x.AwayTeam
+	default:
+		return NotACountry
+	}
+}
+
+// endRegulation records the score at the end of regulation time.
+func (x *Game) endRegulation() {
+	if x.RegulationScore == nil {
+		x.RegulationScore = &Score{Home: x.HomeScore, Away: x.AwayScore}
+	}
+}
+
+// MarshalJSON encodes the game together with its winner, which is omitted while there is none.
+func (x Game) MarshalJSON() ([]byte, error) {
+	// game has the fields of Game without its methods, so encoding it does not call MarshalJSON again.
+	type game Game
+	return json.Marshal(struct {
+		game
+		Winner Countries `json:"winner,omitempty"`
+	}{
+		game:   game(x),
+		Winner: x.Winner(),
+	})
+}
@@ -1,12 +1,26 @@
 package models
 
-// Game represents a game entity with an id, home and away teams, and respective scores.
+import "time"
+
+// Game represents a game entity with an id, home and away teams, respective scores, the time it was started,
+// its status and the timeline of its goals ordered by minute.
+// Scores set directly, e.g. to correct a mistake, are not reflected in the timeline.
+// HomeScore and AwayScore include the goals scored in extra time, but not the penalty shootout.
+// RegulationScore is recorded when regulation time ends by extra time or a shootout, ExtraTimeScore
+// when extra time starts and PenaltyScore when the shootout starts; they are nil for games that did not get there.
 type Game struct {
-	Id        uint32
-	HomeTeam  Countries
-	HomeScore uint
-	AwayTeam  Countries
-	AwayScore uint
+	Id        uint32    `json:"id"`
+	HomeTeam  Countries `json:"home_team"`
+	HomeScore uint      `json:"home_score"`
+	AwayTeam  Countries `json:"away_team"`
+	AwayScore uint      `json:"away_score"`
+	StartedAt time.Time `json:"started_at"`
+	Status    Status    `json:"status"`
+	Goals     []Goal    `json:"goals,omitempty"`
+
+	RegulationScore *Score `json:"regulation_score,omitempty"`
+	ExtraTimeScore  *Score `json:"extra_time_score,omitempty"`
+	PenaltyScore    *Score `json:"penalty_score,omitempty"`
 }
 
 // SetHomeScore sets the home score of the game to the provided value and returns the updated game.
@@ -22,6 +36,7 @@ func (x *Game) SetAwayScore(awayScore uint) *Game {
 }
 
 // GetScore calculates and returns the total score of the game, sum of home and away scores.
+// Goals of extra time count towards the total score and so towards the summary order, the penalty shootout does not.
 func (x *Game) GetScore() uint {
 	return x.HomeScore + x.AwayScore
 }
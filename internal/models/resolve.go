@@ -0,0 +1,184 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxSuggestions is the number of teams suggested for a name that matches no team.
+const maxSuggestions = 3
+
+// UnknownTeamError is returned for a name that matches no active team, with the teams whose names are close to it.
+// It matches ErrInvalidCountry with errors.Is.
+type UnknownTeamError struct {
+	Name        string
+	Suggestions []Countries
+}
+
+// Error returns the message of ErrInvalidCountry with the name and the suggestions, if any.
+func (x *UnknownTeamError) Error() string {
+	if len(x.Suggestions) == 0 {
+		return fmt.Sprintf("%s: %q", ErrInvalidCountry, x.Name)
+	}
+	suggestions := make([]string, 0, len(x.Suggestions))
+	for _, suggestion := range x.Suggestions {
+		suggestions = append(suggestions, string(suggestion))
+	}
+	return fmt.Sprintf("%s: %q, did you mean %s?", ErrInvalidCountry, x.Name, strings.Join(suggestions, ", "))
+}
+
+// Unwrap returns ErrInvalidCountry.
+func (x *UnknownTeamError) Unwrap() error {
+	return ErrInvalidCountry
+}
+
+// ResolveTeam returns the id of the active team of the registry in use matching the name, see TeamRegistry.Resolve.
+func ResolveTeam(name string) (Countries, error) {
+	return Teams().Resolve(name)
+}
+
+// ResolveAnyTeam returns the id of the team, active or retired, of the registry in use matching the name,
+// see TeamRegistry.ResolveAny.
+func ResolveAnyTeam(name string) (Countries, error) {
+	return Teams().ResolveAny(name)
+}
+
+// GameTeam returns the team of the game matching the name by any of the names it is resolved by,
+// whether the team is retired or not, or NotACountry if the name matches neither team of the game.
+func (x *Game) GameTeam(name string) Countries {
+	key := normalizeName(name)
+	if key == "" {
+		return NotACountry
+	}
+	for _, id := range []Countries{x.HomeTeam, x.AwayTeam} {
+		if key == normalizeName(string(id)) {
+			return id
+		}
+		team, ok := Teams().Team(id)
+		if !ok {
+			continue
+		}
+		for _, keys := range teamKeys {
+			for _, teamKey := range keys(team) {
+				if key == normalizeName(teamKey) {
+					return id
+				}
+			}
+		}
+	}
+	return NotACountry
+}
+
+// teamKeys returns the names a team is resolved by, from the most to the least specific: its id and name,
+// its codes, and its aliases. A name claimed by a more specific key of another team is not claimed again.
+var teamKeys = []func(team Team) []string{
+	func(team Team) []string { return []string{string(team.Id), team.Name} },
+	func(team Team) []string { return []string{team.ShortCode, team.IsoCode, team.Iso3Code} },
+	func(team Team) []string { return team.Aliases },
+}
+
+// buildNames returns the normalized names of the active teams, or of all teams with retired set, mapped to their ids.
+// A name two teams share at the same level of teamKeys is ambiguous and resolves to no team.
+func buildNames(teams map[Countries]Team, retired bool) map[string]Countries {
+	names := make(map[string]Countries)
+	ambiguous := make(map[string]bool)
+	for _, keys := range teamKeys {
+		level := make(map[string]Countries)
+		for _, team := range teams {
+			if team.Retired && !retired {
+				continue
+			}
+			for _, key := range keys(team) {
+				key = normalizeName(key)
+				if _, ok := names[key]; ok || key == "" || ambiguous[key] {
+					continue
+				}
+				if other, ok := level[key]; ok && other != team.Id {
+					ambiguous[key] = true
+				}
+				level[key] = team.Id
+			}
+		}
+		for key, id := range level {
+			if !ambiguous[key] {
+				names[key] = id
+			}
+		}
+	}
+	return names
+}
+
+// normalizeName folds the name for matching: diacritics are removed, letters lower-cased, dots and apostrophes dropped
+// and any other run of characters that are not letters or digits replaced by a single space,
+// so "Côte d'Ivoire", "cote divoire" and "COTE-D'IVOIRE" are the same.
+func normalizeName(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+	folded = strings.NewReplacer(".", "", "'", "", "’", "").Replace(strings.ToLower(folded))
+	return strings.Join(strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// suggest returns up to maxSuggestions ids of the teams with a name within a small edit distance of the normalized name,
+// the closest first.
+func suggest(names map[string]Countries, name string) []Countries {
+	maxDistance := 1 + len([]rune(name))/4
+	distances := make(map[Countries]int)
+	for key, id := range names {
+		distance := editDistance(name, key)
+		if distance > maxDistance {
+			continue
+		}
+		if best, ok := distances[id]; !ok || distance < best {
+			distances[id] = distance
+		}
+	}
+
+	var result []Countries
+	for id := range distances {
+		result = append(result, id)
+	}
+	slices.SortFunc(result, func(a, b Countries) int {
+		if c := cmp.Compare(distances[a], distances[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return result[:min(len(result), maxSuggestions)]
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of adjacent characters
+// turning a into b, the optimal string alignment distance.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	rows := make([][]int, len(ar)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(br)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ar)][len(br)]
+}
@@ -0,0 +1,166 @@
+package models
+
+import "time"
+
+// Status defines the stage of the lifecycle a game is in.
+type Status string
+
+const (
+	// StatusScheduled is the status of a game that was scheduled but has not kicked off yet.
+	StatusScheduled Status = "scheduled"
+	// StatusLive is the status of a game being played in regular time.
+	StatusLive Status = "live"
+	// StatusHalfTime is the status of a game in the break between the halves of regular time.
+	StatusHalfTime Status = "half_time"
+	// StatusExtraTime is the status of a game being played in extra time.
+	StatusExtraTime Status = "extra_time"
+	// StatusPenalties is the status of a game decided by a penalty shootout.
+	StatusPenalties Status = "penalties"
+	// StatusFinished is the status of a game that was played to the end.
+	StatusFinished Status = "finished"
+	// StatusAbandoned is the status of a game that was stopped and will not be played to the end.
+	StatusAbandoned Status = "abandoned"
+)
+
+// Transition defines an operation moving a game from one status to another.
+type Transition string
+
+const (
+	// TransitionKickoff starts a scheduled game.
+	TransitionKickoff Transition = "kickoff"
+	// TransitionHalfTime interrupts regular time for the break between the halves.
+	TransitionHalfTime Transition = "half_time"
+	// TransitionResume continues regular time after the break.
+	TransitionResume Transition = "resume"
+	// TransitionExtraTime starts extra time after regular time ended in a draw.
+	TransitionExtraTime Transition = "extra_time"
+	// TransitionShootout starts the penalty shootout after regular or extra time.
+	TransitionShootout Transition = "shootout"
+	// TransitionAbandon stops a game that was not finished yet for good.
+	TransitionAbandon Transition = "abandon"
+)
+
+// AllTransitions contains all transitions a game on the board can be moved through.
+// Finishing a game is not one of them, since it also moves the game from the board to the score base.
+var AllTransitions = []Transition{
+	TransitionKickoff,
+	TransitionHalfTime,
+	TransitionResume,
+	TransitionExtraTime,
+	TransitionShootout,
+	TransitionAbandon,
+}
+
+// transitionRule defines the statuses a transition is allowed from and the status it leads to.
+// A transition with a level rule is only allowed while the game is a draw. Enter, if set, is applied to the game
+// together with the new status.
+type transitionRule struct {
+	from  []Status
+	to    Status
+	level bool
+	enter func(x *Game)
+}
+
+// transitions defines the state machine of the game lifecycle.
+var transitions = map[Transition]transitionRule{
+	TransitionKickoff:  {from: []Status{StatusScheduled}, to: StatusLive},
+	TransitionHalfTime: {from: []Status{StatusLive}, to: StatusHalfTime},
+	TransitionResume:   {from: []Status{StatusHalfTime}, to: StatusLive},
+	TransitionExtraTime: {
+		from:  []Status{StatusLive},
+		to:    StatusExtraTime,
+		level: true,
+		enter: func(x *Game) {
+			x.endRegulation()
+			x.ExtraTimeScore = &Score{}
+		},
+	},
+	TransitionShootout: {
+		from:  []Status{StatusLive, StatusExtraTime},
+		to:    StatusPenalties,
+		level: true,
+		enter: func(x *Game) {
+			x.endRegulation()
+			x.PenaltyScore = &Score{}
+		},
+	},
+	TransitionAbandon: {
+		from: []Status{StatusScheduled, StatusLive, StatusHalfTime, StatusExtraTime, StatusPenalties},
+		to:   StatusAbandoned,
+	},
+}
+
+// finishableStatuses are the statuses a game can be finished from.
+var finishableStatuses = []Status{StatusLive, StatusExtraTime, StatusPenalties}
+
+// AcceptsGoals reports whether goals can be scored in a game with the status.
+func (x Status) AcceptsGoals() bool {
+	return x == StatusLive || x == StatusExtraTime
+}
+
+// AcceptsScoreUpdates reports whether the score of a game with the status can be set directly, e.g. to correct it.
+// The score can be corrected during the breaks and the shootout as well, but not before the kickoff or after the end.
+func (x Status) AcceptsScoreUpdates() bool {
+	switch x {
+	case StatusLive, StatusHalfTime, StatusExtraTime, StatusPenalties:
+		return true
+	default:
+		return false
+	}
+}
+
+// in reports whether the status is one of the provided statuses.
+func (x Status) in(statuses []Status) bool {
+	for _, status := range statuses {
+		if x == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the game to the status the transition leads to.
+// ErrUnknownTransition is returned for an unknown transition and ErrInvalidTransition if the transition
+// is not allowed from the current status of the game, or if extra time or a shootout is started while
+// the game is not a draw; the game is left unchanged in all cases.
+func (x *Game) Transition(transition Transition) error {
+	rule, ok := transitions[transition]
+	if !ok {
+		return ErrUnknownTransition
+	}
+	if !x.Status.in(rule.from) || (rule.level && x.HomeScore != x.AwayScore) {
+		return ErrInvalidTransition
+	}
+	x.Status = rule.to
+	if rule.enter != nil {
+		rule.enter(x)
+	}
+	return nil
+}
+
+// Kickoff moves a scheduled game through TransitionKickoff and sets the time it started at to the kickoff,
+// so a game scheduled in advance is not counted as played since it was put on the board.
+func (x *Game) Kickoff(at time.Time) error {
+	if err := x.Transition(TransitionKickoff); err != nil {
+		return err
+	}
+	x.StartedAt = at
+	return nil
+}
+
+// Finish marks the game as finished before it is recorded in the score base. An abandoned game keeps its status.
+// ErrInvalidTransition is returned if the game has not kicked off yet or is at half-time,
+// and ErrShootoutUndecided if its penalty shootout is a draw.
+func (x *Game) Finish() error {
+	if x.Status == StatusAbandoned {
+		return nil
+	}
+	if !x.Status.in(finishableStatuses) {
+		return ErrInvalidTransition
+	}
+	if x.Status == StatusPenalties && (x.PenaltyScore == nil || x.PenaltyScore.Home == x.PenaltyScore.Away) {
+		return ErrShootoutUndecided
+	}
+	x.Status = StatusFinished
+	return nil
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Team represents a team games can be played by, a national team or a club. Id is the value games are stored with,
+// Name is the name shown to users, ShortCode a three-letter code such as the FIFA code, IsoCode and Iso3Code
+// the ISO 3166 alpha-2 and alpha-3 codes of the country of the team, if any, and Aliases other names of the team.
+// Teams are resolved by any of these names, see TeamRegistry.Resolve.
+// Retired teams keep their past games but cannot start new ones.
+type Team struct {
+	Id        Countries `json:"id" yaml:"id"`
+	Name      string    `json:"name" yaml:"name"`
+	ShortCode string    `json:"short_code,omitempty" yaml:"short_code,omitempty"`
+	IsoCode   string    `json:"iso_code,omitempty" yaml:"iso_code,omitempty"`
+	Iso3Code  string    `json:"iso3_code,omitempty" yaml:"iso3_code,omitempty"`
+	Aliases   []string  `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Retired   bool      `json:"retired,omitempty" yaml:"retired,omitempty"`
+}
+
+// normalize trims the fields of the team, upper-cases its codes, defaults its name to its id
+// and returns ErrInvalidTeam if it has no id made of letters or digits.
+func (x Team) normalize() (Team, error) {
+	x.Id = Countries(strings.TrimSpace(string(x.Id)))
+	if normalizeName(string(x.Id)) == "" {
+		return Team{}, ErrInvalidTeam
+	}
+	x.Name = strings.TrimSpace(x.Name)
+	if x.Name == "" {
+		x.Name = string(x.Id)
+	}
+	x.ShortCode = strings.ToUpper(strings.TrimSpace(x.ShortCode))
+	x.IsoCode = strings.ToUpper(strings.TrimSpace(x.IsoCode))
+	x.Iso3Code = strings.ToUpper(strings.TrimSpace(x.Iso3Code))
+	var aliases []string
+	for _, alias := range x.Aliases {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	x.Aliases = aliases
+	return x, nil
+}
+
+// BuiltinTeams returns the teams of AllCountries, the teams played by when no team registry is configured.
+func BuiltinTeams() []Team {
+	return []Team{
+		{Id: Argentina, Name: "Argentina", ShortCode: "ARG", IsoCode: "AR", Iso3Code: "ARG"},
+		{Id: Australia, Name: "Australia", ShortCode: "AUS", IsoCode: "AU", Iso3Code: "AUS"},
+		{Id: Brazil, Name: "Brazil", ShortCode: "BRA", IsoCode: "BR", Iso3Code: "BRA", Aliases: []string{"Brasil"}},
+		{Id: Canada, Name: "Canada", ShortCode: "CAN", IsoCode: "CA", Iso3Code: "CAN"},
+		{Id: China, Name: "China", ShortCode: "CHN", IsoCode: "CN", Iso3Code: "CHN", Aliases: []string{"China PR"}},
+		{Id: Denmark, Name: "Denmark", ShortCode: "DEN", IsoCode: "DK", Iso3Code: "DNK", Aliases: []string{"Danmark"}},
+		{Id: Egypt, Name: "Egypt", ShortCode: "EGY", IsoCode: "EG", Iso3Code: "EGY"},
+		{Id: France, Name: "France", ShortCode: "FRA", IsoCode: "FR", Iso3Code: "FRA"},
+		{Id: Germany, Name: "Germany", ShortCode: "GER", IsoCode: "DE", Iso3Code: "DEU", Aliases: []string{"Deutschland"}},
+		{Id: India, Name: "India", ShortCode: "IND", IsoCode: "IN", Iso3Code: "IND"},
+		{Id: Indonesia, Name: "Indonesia", ShortCode: "IDN", IsoCode: "ID", Iso3Code: "IDN"},
+		{Id: Italy, Name: "Italy", ShortCode: "ITA", IsoCode: "IT", Iso3Code: "ITA", Aliases: []string{"Italia"}},
+		{Id: Japan, Name: "Japan", ShortCode: "JPN", IsoCode: "JP", Iso3Code: "JPN", Aliases: []string{"Nippon"}},
+		{Id: Morocco, Name: "Morocco", ShortCode: "MAR", IsoCode: "MA", Iso3Code: "MAR", Aliases: []string{"Maroc"}},
+		{Id: Nigeria, Name: "Nigeria", ShortCode: "NGA", IsoCode: "NG", Iso3Code: "NGA"},
+		{Id: Poland, Name: "Poland", ShortCode: "POL", IsoCode: "PL", Iso3Code: "POL", Aliases: []string{"Polska"}},
+		{Id: SouthAfrica, Name: "South Africa", ShortCode: "RSA", IsoCode: "ZA", Iso3Code: "ZAF"},
+		{Id: Spain, Name: "Spain", ShortCode: "ESP", IsoCode: "ES", Iso3Code: "ESP", Aliases: []string{"España"}},
+		{Id: UK, Name: "United Kingdom", ShortCode: "GBR", IsoCode: "GB", Iso3Code: "GBR", Aliases: []string{"Great Britain", "Britain"}},
+		{Id: USA, Name: "USA", ShortCode: "USA", IsoCode: "US", Iso3Code: "USA", Aliases: []string{"United States", "United States of America"}},
+	}
+}
+
+// TeamRegistry keeps the teams games can be played by, indexed by id and by the normalized names they are resolved by,
+// names of the active teams and allNames of all teams, retired included. TeamRegistry is safe for concurrent use.
+type TeamRegistry struct {
+	teams    map[Countries]Team
+	names    map[string]Countries
+	allNames map[string]Countries
+	lock     sync.RWMutex
+}
+
+// NewTeamRegistry returns a new registry of the provided teams. ErrInvalidTeam is returned for a team without an id
+// and ErrDuplicateTeam if two teams share an id, ignoring case and diacritics, or a short code.
+func NewTeamRegistry(teams ...Team) (*TeamRegistry, error) {
+	registry := &TeamRegistry{teams: make(map[Countries]Team, len(teams))}
+	for _, team := range teams {
+		team, err := team.normalize()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := registry.sameId(team); ok || registry.hasShortCode(team) {
+			return nil, ErrDuplicateTeam
+		}
+		registry.teams[team.Id] = team
+	}
+	registry.indexNames()
+	return registry, nil
+}
+
+// Resolve returns the id of the active team matching the name. The name is matched, ignoring case, diacritics,
+// punctuation and extra whitespace, against the id, the name, the short code, the ISO codes and the aliases of the teams.
+// For a name matching no active team an *UnknownTeamError is returned, suggesting the teams with similar names.
+func (x *TeamRegistry) Resolve(name string) (Countries, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return resolve(x.names, name)
+}
+
+// ResolveAny returns the id of the team, active or retired, matching the name the same way as Resolve,
+// e.g. for looking up the games a retired team played.
+func (x *TeamRegistry) ResolveAny(name string) (Countries, error) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	return resolve(x.allNames, name)
+}
+
+// resolve returns the id the normalized name is mapped to in names, otherwise an *UnknownTeamError
+// suggesting the teams of names with similar names.
+func resolve(names map[string]Countries, name string) (Countries, error) {
+	key := normalizeName(name)
+	if id, ok := names[key]; ok {
+		return id, nil
+	}
+	var suggestions []Countries
+	if key != "" {
+		suggestions = suggest(names, key)
+	}
+	return NotACountry, &UnknownTeamError{Name: name, Suggestions: suggestions}
+}
+
+// Team returns the team with the provided id, retired or not, and reports whether it was found.
+func (x *TeamRegistry) Team(id Countries) (Team, bool) {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	team, ok := x.teams[id]
+	return team, ok
+}
+
+// Teams returns all teams, including the retired ones, ordered by id.
+func (x *TeamRegistry) Teams() []Team {
+	x.lock.RLock()
+	result := make([]Team, 0, len(x.teams))
+	for _, team := range x.teams {
+		result = append(result, team)
+	}
+	x.lock.RUnlock()
+
+	slices.SortFunc(result, func(a, b Team) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return result
+}
+
+// Active returns the teams that are not retired, ordered by id.
+func (x *TeamRegistry) Active() []Team {
+	return slices.DeleteFunc(x.Teams(), func(team Team) bool {
+		return team.Retired
+	})
+}
+
+// AddTeam adds the team to the registry and returns it as it was stored. A retired team is brought back
+// by adding it again. ErrInvalidTeam is returned for a team without an id and ErrDuplicateTeam if an active team
+// has the same id, ignoring case and diacritics, or another team the same short code.
+func (x *TeamRegistry) AddTeam(team Team) (Team, error) {
+	team, err := team.normalize()
+	if err != nil {
+		return Team{}, err
+	}
+	team.Retired = false
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	existing, ok := x.sameId(team)
+	if ok && (!existing.Retired || existing.Id != team.Id) {
+		return Team{}, ErrDuplicateTeam
+	}
+	if x.hasShortCode(team) {
+		return Team{}, ErrDuplicateTeam
+	}
+	x.teams[team.Id] = team
+	x.indexNames()
+	return team, nil
+}
+
+// RetireTeam retires the team with the provided id, so it cannot start new games, and returns it. Games already played
+// or in progress are not affected. ErrTeamNotFound is returned if there is no active team with the id.
+func (x *TeamRegistry) RetireTeam(id Countries) (Team, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	team, ok := x.teams[id]
+	if !ok || team.Retired {
+		return Team{}, ErrTeamNotFound
+	}
+	team.Retired = true
+	x.teams[id] = team
+	x.indexNames()
+	return team, nil
+}
+
+// indexNames rebuilds the indexes of the names of the active teams and of all teams. The lock must be held by the caller.
+func (x *TeamRegistry) indexNames() {
+	x.names = buildNames(x.teams, false)
+	x.allNames = buildNames(x.teams, true)
+}
+
+// sameId returns the team whose id is the id of the provided team, ignoring case and diacritics,
+// and reports whether it was found. The lock must be held by the caller.
+func (x *TeamRegistry) sameId(team Team) (Team, bool) {
+	if existing, ok := x.teams[team.Id]; ok {
+		return existing, true
+	}
+	key := normalizeName(string(team.Id))
+	for _, existing := range x.teams {
+		if normalizeName(string(existing.Id)) == key {
+			return existing, true
+		}
+	}
+	return Team{}, false
+}
+
+// hasShortCode reports whether a team other than the provided one has its short code. The lock must be held by the caller.
+func (x *TeamRegistry) hasShortCode(team Team) bool {
+	if team.ShortCode == "" {
+		return false
+	}
+	for _, other := range x.teams {
+		if other.Id != team.Id && other.ShortCode == team.ShortCode {
+			return true
+		}
+	}
+	return false
+}
+
+// teams is the registry GetCountryFromString and ResolveTeam resolve teams by.
+var teams atomic.Pointer[TeamRegistry]
+
+func init() {
+	registry, err := NewTeamRegistry(BuiltinTeams()...)
+	if err != nil {
+		panic(err)
+	}
+	teams.Store(registry)
+}
+
+// Teams returns the registry in use, the one of the built-in teams unless it was replaced by SetTeams.
+func Teams() *TeamRegistry {
+	return teams.Load()
+}
+
+// SetTeams replaces the registry in use, e.g. by the one loaded from the team config file at startup.
+func SetTeams(registry *TeamRegistry) {
+	teams.Store(registry)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBuiltinTeams(t *testing.T) {
+	registry, err := NewTeamRegistry(BuiltinTeams()...)
+	assert.NoError(t, err)
+	assert.Equal(t, len(AllCountries), len(registry.Teams()))
+	for _, id := range AllCountries {
+		_, ok := registry.Team(id)
+		assert.Equal(t, true, ok, id)
+	}
+
+	tests := []struct {
+		name string
+		want Countries
+	}{
+		{name: "Spain", want: Spain},
+		{name: "españa", want: Spain},
+		{name: "DEU", want: Germany},
+		{name: "ger", want: Germany},
+		{name: "Brasil", want: Brazil},
+		{name: "United States of America", want: USA},
+		{name: "Great Britain", want: UK},
+		{name: "GBR", want: UK},
+		// England, Scotland and Wales play as teams of their own, none of them is the United Kingdom.
+		{name: "England", want: NotACountry},
+		{name: "Narnia", want: NotACountry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, err := registry.Resolve(tt.name)
+			assert.Equal(t, tt.want, team)
+			if tt.want == NotACountry {
+				assert.ErrorIs(t, err, ErrInvalidCountry)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"time"
+)
+
+// ObservedBoard wraps a GameBoard and publishes an event to the EventBroker after every successful change.
+type ObservedBoard struct {
+	board  GameBoard
+	events *EventBroker
+}
+
+// NewObservedBoard returns a new instance of ObservedBoard publishing changes of the provided board to events.
+func NewObservedBoard(board GameBoard, events *EventBroker) *ObservedBoard {
+	return &ObservedBoard{
+		board:  board,
+		events: events,
+	}
+}
+
+// StartGame starts the game on the wrapped board and publishes EventMatchStarted.
+func (x *ObservedBoard) StartGame(homeTeam, awayTeam string) (*models.Game, error) {
+	game, err := x.board.StartGame(homeTeam, awayTeam)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventMatchStarted, game)
+	return game, nil
+}
+
+// RemoveGame removes the game from the wrapped board and publishes EventMatchFinished.
+func (x *ObservedBoard) RemoveGame(id uint32) (*models.Game, error) {
+	game, err := x.board.RemoveGame(id)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventMatchFinished, game)
+	return game, nil
+}
+
+// RestoreGame puts the game back on the wrapped board and publishes EventMatchStarted,
+// or EventMatchScheduled for a game that did not kick off.
+func (x *ObservedBoard) RestoreGame(game *models.Game) error {
+	if err := x.board.RestoreGame(game); err != nil {
+		return err
+	}
+	if game.Status == models.StatusScheduled {
+		x.events.Publish(EventMatchScheduled, game)
+	} else {
+		x.events.Publish(EventMatchStarted, game)
+	}
+	return nil
+}
+
+// UpdateGame updates the game on the wrapped board and publishes EventScoreUpdated.
+func (x *ObservedBoard) UpdateGame(id uint32, homeScore, awayScore uint) (*models.Game, error) {
+	game, err := x.board.UpdateGame(id, homeScore, awayScore)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventScoreUpdated, game)
+	return game, nil
+}
+
+// CompareAndSwapScore updates the game on the wrapped board, if it still has the old score, and publishes EventScoreUpdated.
+func (x *ObservedBoard) CompareAndSwapScore(id uint32, old models.Score, homeScore, awayScore uint) (*models.Game, error) {
+	game, err := x.board.CompareAndSwapScore(id, old, homeScore, awayScore)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventScoreUpdated, game)
+	return game, nil
+}
+
+// RecordGoal records the goal on the wrapped board and publishes EventGoalScored.
+func (x *ObservedBoard) RecordGoal(id uint32, goal models.Goal) (*models.Game, error) {
+	game, err := x.board.RecordGoal(id, goal)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventGoalScored, game)
+	return game, nil
+}
+
+// ScheduleGame schedules the game on the wrapped board and publishes EventMatchScheduled.
+func (x *ObservedBoard) ScheduleGame(homeTeam, awayTeam string) (*models.Game, error) {
+	game, err := x.board.ScheduleGame(homeTeam, awayTeam)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventMatchScheduled, game)
+	return game, nil
+}
+
+// UpdateShootout updates the penalty shootout score on the wrapped board and publishes EventScoreUpdated.
+func (x *ObservedBoard) UpdateShootout(id uint32, homePenalties, awayPenalties uint) (*models.Game, error) {
+	game, err := x.board.UpdateShootout(id, homePenalties, awayPenalties)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventScoreUpdated, game)
+	return game, nil
+}
+
+// TransitionGame moves the game on the wrapped board through the transition and publishes EventStatusChanged.
+func (x *ObservedBoard) TransitionGame(id uint32, transition models.Transition) (*models.Game, error) {
+	game, err := x.board.TransitionGame(id, transition)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventStatusChanged, game)
+	return game, nil
+}
+
+// KickoffGame kicks off the scheduled game on the wrapped board and publishes EventStatusChanged.
+func (x *ObservedBoard) KickoffGame(id uint32, at time.Time) (*models.Game, error) {
+	game, err := x.board.KickoffGame(id, at)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventStatusChanged, game)
+	return game, nil
+}
+
+// GetGame returns the game with the provided id of the wrapped board.
+func (x *ObservedBoard) GetGame(id uint32) (*models.Game, error) {
+	return x.board.GetGame(id)
+}
+
+// GetGames returns all games of the wrapped board.
+func (x *ObservedBoard) GetGames() []*models.Game {
+	return x.board.GetGames()
+}
+
+// ObservedScoreBase wraps a ScoreBaseStoring and publishes an event to the EventBroker after every insert.
+type ObservedScoreBase struct {
+	store  ScoreBaseStoring
+	events *EventBroker
+}
+
+// NewObservedScoreBase returns a new instance of ObservedScoreBase publishing inserts into the provided store to events.
+func NewObservedScoreBase(store ScoreBaseStoring, events *EventBroker) *ObservedScoreBase {
+	return &ObservedScoreBase{
+		store:  store,
+		events: events,
+	}
+}
+
+// Insert inserts the game into the wrapped store and publishes EventMatchRecorded.
+func (x *ObservedScoreBase) Insert(value *models.Game) error {
+	if err := x.store.Insert(value); err != nil {
+		return err
+	}
+	x.events.Publish(EventMatchRecorded, value)
+	return nil
+}
+
+// GetGame returns the game with the provided id of the wrapped store.
+func (x *ObservedScoreBase) GetGame(id uint32) (*models.Game, error) {
+	return x.store.GetGame(id)
+}
+
+// GetGames returns all games of the wrapped store.
+func (x *ObservedScoreBase) GetGames() []*models.Game {
+	return x.store.GetGames()
+}
+
+// Query returns a page of the games of the wrapped store.
+func (x *ObservedScoreBase) Query(query SummaryQuery) (SummaryPage, error) {
+	return x.store.Query(query)
+}
+
+// ObservedFinisher wraps a GameFinisher and publishes both EventMatchFinished and EventMatchRecorded
+// after every finished game, the same events as published by ObservedBoard and ObservedScoreBase.
+type ObservedFinisher struct {
+	finisher GameFinisher
+	events   *EventBroker
+}
+
+// NewObservedFinisher returns a new instance of ObservedFinisher publishing games finished by the provided finisher to events.
+func NewObservedFinisher(finisher GameFinisher, events *EventBroker) *ObservedFinisher {
+	return &ObservedFinisher{
+		finisher: finisher,
+		events:   events,
+	}
+}
+
+// FinishGame finishes the game with the wrapped finisher and publishes EventMatchFinished and EventMatchRecorded.
+func (x *ObservedFinisher) FinishGame(id uint32) (*models.Game, error) {
+	game, err := x.finisher.FinishGame(id)
+	if err != nil {
+		return nil, err
+	}
+	x.events.Publish(EventMatchFinished, game)
+	x.events.Publish(EventMatchRecorded, game)
+	return game, nil
+}
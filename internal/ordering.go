@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"cmp"
+	"github.com/Marian2701/CodingExercise/internal/models"
+)
+
+// GameComparator defines the ordering of lists of games, both active and finished ones.
+// It returns a negative number when a comes before b, a positive number when a comes after b and zero otherwise.
+type GameComparator func(a, b *models.Game) int
+
+// ByScoreThenRecency is the default ordering of games: by total score, highest first;
+// games with equal total scores are ordered by start time, most recently started first,
+// and games started at the same time by id, highest first. Since ids are unique, the ordering is deterministic.
+func ByScoreThenRecency(a, b *models.Game) int {
+	if c := cmp.Compare(b.GetScore(), a.GetScore()); c != 0 {
+		return c
+	}
+	if c := b.StartedAt.Compare(a.StartedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.Id, a.Id)
+}
+
+// ByStartTime orders games by start time, the earliest started first, and games started at the same time by id,
+// lowest first, which is the order the games were played in.
+func ByStartTime(a, b *models.Game) int {
+	if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Id, b.Id)
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+)
+
+// GameRecorder defines a method recording a finished game, e.g. in a league table or a statistics index,
+// and reporting whether the game changed the recorder. Record must ignore games recorded before, by id,
+// since a game can be recorded both by a RecordingScoreBase and a RecordingFinisher.
+type GameRecorder interface {
+	Record(game *models.Game) bool
+}
+
+// RecordingScoreBase wraps a ScoreBaseStoring and records every inserted game in the recorders,
+// so they are updated incrementally instead of being recomputed from all finished games.
+type RecordingScoreBase struct {
+	store     ScoreBaseStoring
+	recorders []GameRecorder
+}
+
+// NewRecordingScoreBase returns a new instance of RecordingScoreBase recording inserts into the provided store
+// in the recorders. The games stored already are recorded before it returns, oldest first, in the order they were
+// played rather than the order of the store.
+func NewRecordingScoreBase(store ScoreBaseStoring, recorders ...GameRecorder) *RecordingScoreBase {
+	games := slices.Clone(store.GetGames())
+	slices.SortFunc(games, ByStartTime)
+	for _, game := range games {
+		for _, recorder := range recorders {
+			recorder.Record(game)
+		}
+	}
+	return &RecordingScoreBase{
+		store:     store,
+		recorders: recorders,
+	}
+}
+
+// Insert inserts the game into the wrapped store and records it in the recorders.
+func (x *RecordingScoreBase) Insert(value *models.Game) error {
+	if err := x.store.Insert(value); err != nil {
+		return err
+	}
+	for _, recorder := range x.recorders {
+		recorder.Record(value)
+	}
+	return nil
+}
+
+// GetGame returns the game with the provided id of the wrapped store.
+func (x *RecordingScoreBase) GetGame(id uint32) (*models.Game, error) {
+	return x.store.GetGame(id)
+}
+
+// GetGames returns the games of the wrapped store.
+func (x *RecordingScoreBase) GetGames() []*models.Game {
+	return x.store.GetGames()
+}
+
+// Query queries the wrapped store.
+func (x *RecordingScoreBase) Query(query SummaryQuery) (SummaryPage, error) {
+	return x.store.Query(query)
+}
+
+// RecordingFinisher wraps a GameFinisher storing finished games without a ScoreBaseStoring, e.g. SQLStore,
+// and records every finished game in the recorders, the same way as RecordingScoreBase.
+type RecordingFinisher struct {
+	finisher  GameFinisher
+	recorders []GameRecorder
+}
+
+// NewRecordingFinisher returns a new instance of RecordingFinisher recording games finished by the provided finisher
+// in the recorders.
+func NewRecordingFinisher(finisher GameFinisher, recorders ...GameRecorder) *RecordingFinisher {
+	return &RecordingFinisher{
+		finisher:  finisher,
+		recorders: recorders,
+	}
+}
+
+// FinishGame finishes the game with the wrapped finisher and records it in the recorders.
+func (x *RecordingFinisher) FinishGame(id uint32) (*models.Game, error) {
+	game, err := x.finisher.FinishGame(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, recorder := range x.recorders {
+		recorder.Record(game)
+	}
+	return game, nil
+}
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/league"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/Marian2701/CodingExercise/internal/stats"
+	"github.com/Marian2701/CodingExercise/internal/tournament"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func newTestLeague(t *testing.T) *league.Table {
+	table, err := league.New(league.ThreePointsForWin, league.DefaultTieBreakers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestRecordingScoreBase(t *testing.T) {
+	store := NewScoreBase()
+	assert.NoError(t, store.Insert(&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, Status: models.StatusFinished}))
+
+	table := newTestLeague(t)
+	scoreBase := NewRecordingScoreBase(store, table)
+	assert.Equal(t, 2, len(table.Standings()))
+
+	assert.NoError(t, scoreBase.Insert(&models.Game{Id: 2, HomeTeam: models.Brazil, HomeScore: 3, AwayTeam: models.Japan, Status: models.StatusFinished}))
+	rows := table.Standings()
+	assert.Equal(t, 3, len(rows))
+	assert.Equal(t, models.Spain, rows[0].Team)
+	assert.Equal(t, models.Brazil, rows[1].Team)
+	assert.Equal(t, uint(3), rows[1].Points)
+	assert.Equal(t, 2, len(scoreBase.GetGames()))
+}
+
+func TestRecordingFinisher(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	board := store.Board()
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 0, 1)
+	assert.NoError(t, err)
+
+	table := newTestLeague(t)
+	finisher := NewRecordingFinisher(store, table)
+	_, err = finisher.FinishGame(game.Id)
+	assert.NoError(t, err)
+	_, err = finisher.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	rows := table.Standings()
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, models.Brazil, rows[0].Team)
+	assert.Equal(t, uint(1), rows[0].Won)
+}
+
+func TestApi_League(t *testing.T) {
+	table, err := league.New(league.TwoPointsForWin)
+	assert.NoError(t, err)
+	board := NewScoreBoard()
+	app := NewApp(NewRecordingScoreBase(NewScoreBase(), table), board, WithLeague(table))
+	app.InitRoutes()
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spain", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 2, "away_score": 1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/league", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Points league.PointsRule `json:"points"`
+		Rows   []league.Row      `json:"rows"`
+	}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, league.TwoPointsForWin, body.Points)
+	assert.Equal(t, 2, len(body.Rows))
+	assert.Equal(t, league.Row{Position: 1, Team: models.Spain, Played: 1, Won: 1, GoalsFor: 2, GoalsAgainst: 1, GoalDifference: 1, Points: 2}, body.Rows[0])
+
+	rec = doRequest(app, http.MethodGet, "/league", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Points for a win, a draw and a loss: 2-1-0")
+	assert.Contains(t, rec.Body.String(), "<td>1</td><td>Spain</td><td>1</td><td>1</td><td>0</td><td>0</td><td>2</td><td>1</td><td>1</td><td>2</td>")
+
+	rec = doRequest(app, http.MethodGet, "/", "")
+	assert.Contains(t, rec.Body.String(), `<a href="/league">League table</a>`)
+}
+
+func TestApi_TeamStats(t *testing.T) {
+	index := stats.NewIndex()
+	board := NewScoreBoard()
+	app := NewApp(NewRecordingScoreBase(NewScoreBase(), index), board, WithStats(index))
+	app.InitRoutes()
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "South Africa", "away_team": "Brazil"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	rec = doRequest(app, http.MethodPatch, "/api/v1/matches/1", `{"home_score": 3, "away_score": 1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches/1/finish", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/teams/South%20Africa/stats", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var teamStats stats.TeamStats
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&teamStats))
+	assert.Equal(t, models.SouthAfrica, teamStats.Team)
+	assert.Equal(t, uint(1), teamStats.Won)
+	assert.Equal(t, uint(1), teamStats.CurrentWinStreak)
+	assert.Equal(t, uint32(1), teamStats.BiggestWin.Id)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/teams/Brazil/head-to-head/South%20Africa", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var record stats.HeadToHead
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&record))
+	assert.Equal(t, uint(1), record.OpponentWins)
+	assert.Equal(t, uint(1), record.TeamGoals)
+	assert.Equal(t, 1, len(record.Games))
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "Invalid team", path: "/api/v1/teams/Narnia/stats", want: http.StatusBadRequest},
+		{name: "Invalid opponent", path: "/api/v1/teams/Brazil/head-to-head/Narnia", want: http.StatusBadRequest},
+		{name: "Same team", path: "/api/v1/teams/Brazil/head-to-head/Brazil", want: http.StatusBadRequest},
+		{name: "Team without games", path: "/api/v1/teams/Japan/stats", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, http.MethodGet, tt.path, "")
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestApi_Tournament(t *testing.T) {
+	cup, err := tournament.New(
+		tournament.Group{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}},
+		tournament.Group{Name: "B", Teams: []models.Countries{models.Germany, models.France}},
+	)
+	assert.NoError(t, err)
+	app := NewApp(NewRecordingScoreBase(NewScoreBase(), cup), NewScoreBoard(), WithTournament(cup))
+	app.InitRoutes()
+
+	play := func(homeTeam string, homeScore uint, awayTeam string, awayScore uint) {
+		rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "`+homeTeam+`", "away_team": "`+awayTeam+`"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var game models.Game
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&game))
+		id := strconv.FormatUint(uint64(game.Id), 10)
+		rec = doRequest(app, http.MethodPatch, "/api/v1/matches/"+id, fmt.Sprintf(`{"home_score": %d, "away_score": %d}`, homeScore, awayScore))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = doRequest(app, http.MethodPost, "/api/v1/matches/"+id+"/finish", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	play("Spain", 2, "Brazil", 0)
+	play("Germany", 0, "France", 1)
+
+	rec := doRequest(app, http.MethodGet, "/api/v1/tournament", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body tournamentResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, []tournament.Pairing{
+		{Group: "A", Round: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil},
+		{Group: "B", Round: 1, HomeTeam: models.Germany, AwayTeam: models.France},
+	}, body.Fixtures)
+	assert.Equal(t, 2, len(body.Standings))
+	assert.Equal(t, models.France, body.Standings[1].Rows[0].Team)
+	assert.Nil(t, body.Knockout)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/tournament/knockout", `{`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/tournament/knockout", `{"after": 2}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	play("Germany", 2, "Spain", 1)
+	play("France", 0, "Brazil", 1)
+	play("Brazil", 3, "Germany", 0)
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/tournament", "")
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, models.Germany, body.Knockout.Rounds[0][0].Winner)
+	assert.Equal(t, models.Brazil, body.Knockout.Champion())
+}
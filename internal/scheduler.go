@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// schedulerRetryInterval is the time the Scheduler waits before promoting due fixtures again after a failure.
+const schedulerRetryInterval = 10 * time.Second
+
+// Clock defines the source of time of the Scheduler, so tests can move time forward without waiting.
+// After returns a channel receiving the time once the duration has elapsed.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock reading the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and sends the current time on the returned channel.
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Scheduler keeps fixtures scheduled in advance and starts their games on a GameBoard at kickoff.
+// Fixtures are promoted by Run, which has to be running for scheduled games to start.
+type Scheduler struct {
+	fixtures FixtureStoring
+	board    GameBoard
+	clock    Clock
+	wake     chan struct{}
+	lock     sync.Mutex
+	logger   *log.Logger
+}
+
+// NewScheduler returns a new instance of Scheduler keeping fixtures in the provided store
+// and starting their games on the board at the kickoff time read from the clock.
+func NewScheduler(fixtures FixtureStoring, board GameBoard, clock Clock) *Scheduler {
+	return &Scheduler{
+		fixtures: fixtures,
+		board:    board,
+		clock:    clock,
+		wake:     make(chan struct{}, 1),
+		logger:   log.New(os.Stdout, "", log.LstdFlags),
+	}
+}
+
+// AddFixture schedules a fixture and makes Run reconsider the next kickoff, which may be the one of the new fixture.
+// A fixture with a kickoff time in the past is promoted right away.
+func (x *Scheduler) AddFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error) {
+	fixture, err := x.fixtures.AddFixture(homeTeam, awayTeam, kickoffAt, venue)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case x.wake <- struct{}{}:
+	default:
+	}
+	return fixture, nil
+}
+
+// Upcoming returns the fixtures that were not promoted yet ordered by kickoff time.
+func (x *Scheduler) Upcoming() ([]*models.Fixture, error) {
+	return x.fixtures.Upcoming()
+}
+
+// Promoted returns the fixture the game with the provided id was started from at kickoff,
+// or models.ErrFixtureNotFound if it was started right away.
+func (x *Scheduler) Promoted(gameId uint32) (*models.Fixture, error) {
+	return x.fixtures.Promoted(gameId)
+}
+
+// Scheduled reports whether the team with the provided id plays in one of the upcoming fixtures,
+// e.g. before retiring the team.
+func (x *Scheduler) Scheduled(team models.Countries) (bool, error) {
+	upcoming, err := x.fixtures.Upcoming()
+	if err != nil {
+		return false, err
+	}
+	for _, fixture := range upcoming {
+		if fixture.HomeTeam == team || fixture.AwayTeam == team {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PromoteDue starts the games of all fixtures whose kickoff time has come and returns the kickoff time
+// of the next fixture, or the zero time if no fixtures are left. A fixture with a team that is still playing
+// in another game, or that cannot play anymore because it was retired, stays upcoming and is retried later
+// without holding up the fixtures after it; the error of the first such fixture is returned together with
+// the next kickoff time in that case.
+// If a fixture cannot be marked as promoted, the error is returned and its game stays on the board,
+// where it is found again by the next call, e.g. by Run after a restart, instead of being started twice.
+func (x *Scheduler) PromoteDue() (time.Time, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	upcoming, err := x.fixtures.Upcoming()
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := x.clock.Now()
+	var postponed error
+	for _, fixture := range upcoming {
+		if fixture.KickoffAt.After(now) {
+			return fixture.KickoffAt, postponed
+		}
+		game, err := x.kickoff(fixture, now)
+		if errors.Is(err, models.ErrTeamAlreadyPlaying) || errors.Is(err, models.ErrInvalidCountry) {
+			if postponed == nil {
+				postponed = fmt.Errorf("start game of fixture %d: %w", fixture.Id, err)
+			}
+			continue
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("start game of fixture %d: %w", fixture.Id, err)
+		}
+		if err := x.fixtures.Promote(fixture.Id, game.Id); err != nil {
+			return time.Time{}, fmt.Errorf("promote fixture %d: %w", fixture.Id, err)
+		}
+	}
+	return time.Time{}, postponed
+}
+
+// kickoff schedules the game of the fixture on the board and kicks it off at the provided time, the same way
+// as a game scheduled by hand. The fixture is marked as Promoting before the game is scheduled, so the game
+// of a fixture that was being promoted when the process stopped is looked up on the board and only kicked off
+// if it is still scheduled.
+func (x *Scheduler) kickoff(fixture *models.Fixture, now time.Time) (*models.Game, error) {
+	var game *models.Game
+	var err error
+	if fixture.Promoting {
+		game, err = x.startedGame(fixture)
+	} else {
+		err = x.fixtures.BeginPromote(fixture.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		game, err = x.board.ScheduleGame(string(fixture.HomeTeam), string(fixture.AwayTeam))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if game.Status != models.StatusScheduled {
+		return game, nil
+	}
+	return x.board.KickoffGame(game.Id, now)
+}
+
+// startedGame returns the game between the teams of the fixture on the board that was not promoted
+// from another fixture, or nil if there is none. A team plays in a single game on the board at a time,
+// so there is at most one such game.
+func (x *Scheduler) startedGame(fixture *models.Fixture) (*models.Game, error) {
+	for _, game := range x.board.GetGames() {
+		if game.HomeTeam != fixture.HomeTeam || game.AwayTeam != fixture.AwayTeam {
+			continue
+		}
+		_, err := x.fixtures.Promoted(game.Id)
+		if errors.Is(err, models.ErrFixtureNotFound) {
+			return game, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// Run promotes due fixtures at their kickoff time until the context is done.
+// It waits for the next kickoff or a new fixture, and retries after schedulerRetryInterval when promoting fails,
+// or earlier if the next kickoff comes first.
+func (x *Scheduler) Run(ctx context.Context) {
+	for {
+		var timer <-chan time.Time
+		next, err := x.PromoteDue()
+		if err != nil {
+			x.logger.Println("failed to promote fixtures: ", err)
+			retry := schedulerRetryInterval
+			if !next.IsZero() {
+				retry = min(retry, next.Sub(x.clock.Now()))
+			}
+			timer = x.clock.After(retry)
+		} else if !next.IsZero() {
+			timer = x.clock.After(next.Sub(x.clock.Now()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer:
+		case <-x.wake:
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package internal
+
+import (
+	"context"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when Advance is called.
+type fakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+// fakeTimer is a channel waiting for the fake time to reach the deadline.
+type fakeTimer struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (x *fakeClock) Now() time.Time {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	return x.now
+}
+
+func (x *fakeClock) After(d time.Duration) <-chan time.Time {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	timer := fakeTimer{deadline: x.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		timer.c <- x.now
+		return timer.c
+	}
+	x.timers = append(x.timers, timer)
+	return timer.c
+}
+
+// Advance moves the time forward and fires the timers whose deadline has come.
+func (x *fakeClock) Advance(d time.Duration) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	x.now = x.now.Add(d)
+	waiting := x.timers[:0]
+	for _, timer := range x.timers {
+		if timer.deadline.After(x.now) {
+			waiting = append(waiting, timer)
+			continue
+		}
+		timer.c <- x.now
+	}
+	x.timers = waiting
+}
+
+// assertFixtures adds fixtures to the store, promotes one of them and checks the upcoming and promoted fixtures.
+func assertFixtures(t *testing.T, fixtures FixtureStoring) {
+	kickoff := time.Date(2026, 6, 11, 20, 0, 0, 0, time.UTC)
+
+	_, err := fixtures.AddFixture("Spain", "Narnia", kickoff, "")
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+	_, err = fixtures.AddFixture("Spain", "Brazil", time.Time{}, "")
+	assert.ErrorIs(t, err, models.ErrInvalidKickoff)
+
+	late, err := fixtures.AddFixture("Spain", "Brazil", kickoff.Add(time.Hour), " Estadio Azteca ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Estadio Azteca", late.Venue)
+	early, err := fixtures.AddFixture("Germany", "France", kickoff, "")
+	assert.NoError(t, err)
+	promoted, err := fixtures.AddFixture("Italy", "Japan", kickoff, "Wembley")
+	assert.NoError(t, err)
+
+	assert.NoError(t, fixtures.BeginPromote(late.Id))
+	assert.NoError(t, fixtures.BeginPromote(promoted.Id))
+	assert.NoError(t, fixtures.Promote(promoted.Id, 7))
+	assert.ErrorIs(t, fixtures.Promote(promoted.Id, 8), models.ErrFixtureNotFound)
+	assert.ErrorIs(t, fixtures.Promote(99, 8), models.ErrFixtureNotFound)
+	assert.ErrorIs(t, fixtures.BeginPromote(promoted.Id), models.ErrFixtureNotFound)
+	assert.ErrorIs(t, fixtures.BeginPromote(99), models.ErrFixtureNotFound)
+
+	upcoming, err := fixtures.Upcoming()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(upcoming))
+	assert.Equal(t, early.Id, upcoming[0].Id)
+	assert.Equal(t, late.Id, upcoming[1].Id)
+	assert.Equal(t, models.Spain, upcoming[1].HomeTeam)
+	assert.Equal(t, true, kickoff.Add(time.Hour).Equal(upcoming[1].KickoffAt))
+	assert.Equal(t, false, upcoming[0].Promoting)
+	assert.Equal(t, true, upcoming[1].Promoting)
+	assert.Equal(t, false, late.Promoting)
+
+	fixture, err := fixtures.Promoted(7)
+	assert.NoError(t, err)
+	assert.Equal(t, promoted.Id, fixture.Id)
+	assert.Equal(t, uint32(7), fixture.GameId)
+	assert.Equal(t, "Wembley", fixture.Venue)
+	assert.Equal(t, false, fixture.Promoting)
+	_, err = fixtures.Promoted(8)
+	assert.ErrorIs(t, err, models.ErrFixtureNotFound)
+}
+
+func TestMemoryFixtures(t *testing.T) {
+	assertFixtures(t, NewMemoryFixtures())
+}
+
+func TestFileFixtures(t *testing.T) {
+	dir := t.TempDir()
+	fixtures, err := NewFileFixtures(dir)
+	assert.NoError(t, err)
+	assertFixtures(t, fixtures)
+	want, err := fixtures.Upcoming()
+	assert.NoError(t, err)
+	assert.NoError(t, fixtures.Close())
+
+	reopened, err := NewFileFixtures(dir)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	got, err := reopened.Upcoming()
+	assert.NoError(t, err)
+	assert.Equal(t, len(want), len(got))
+	for i := range want {
+		assert.Equal(t, want[i].Id, got[i].Id)
+		assert.Equal(t, want[i].Venue, got[i].Venue)
+		assert.Equal(t, want[i].Promoting, got[i].Promoting)
+		assert.Equal(t, true, want[i].KickoffAt.Equal(got[i].KickoffAt))
+	}
+	fixture, err := reopened.Promoted(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "Wembley", fixture.Venue)
+
+	// The id of the promoted fixture must not be reused.
+	fixture, err = reopened.AddFixture("Spain", "Brazil", time.Now(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4), fixture.Id)
+}
+
+func TestSQLFixtures(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	assertFixtures(t, store.Fixtures())
+}
+
+func TestScheduler_PromoteDue(t *testing.T) {
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+
+	_, err := scheduler.AddFixture("Spain", "Brazil", start.Add(2*time.Hour), "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Germany", "France", start.Add(time.Hour), "")
+	assert.NoError(t, err)
+
+	next, err := scheduler.PromoteDue()
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), next)
+	assert.Equal(t, 0, len(board.GetGames()))
+
+	clock.Advance(time.Hour)
+	next, err = scheduler.PromoteDue()
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(2*time.Hour), next)
+	games := board.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, models.Germany, games[0].HomeTeam)
+	assert.Equal(t, models.StatusLive, games[0].Status)
+	assert.Equal(t, true, start.Add(time.Hour).Equal(games[0].StartedAt))
+	fixture, err := scheduler.Promoted(games[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Germany, fixture.HomeTeam)
+
+	clock.Advance(time.Hour)
+	next, err = scheduler.PromoteDue()
+	assert.NoError(t, err)
+	assert.Equal(t, true, next.IsZero())
+	assert.Equal(t, 2, len(board.GetGames()))
+	upcoming, err := scheduler.Upcoming()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(upcoming))
+}
+
+// unkickableBoard is a ScoreBoard failing to kick off games while fail is set.
+type unkickableBoard struct {
+	*ScoreBoard
+	fail bool
+}
+
+func (x *unkickableBoard) KickoffGame(id uint32, at time.Time) (*models.Game, error) {
+	if x.fail {
+		return nil, assert.AnError
+	}
+	return x.ScoreBoard.KickoffGame(id, at)
+}
+
+// unpromotableFixtures is a MemoryFixtures failing to promote fixtures while fail is set.
+type unpromotableFixtures struct {
+	*MemoryFixtures
+	fail bool
+}
+
+func (x *unpromotableFixtures) Promote(id, gameId uint32) error {
+	if x.fail {
+		return assert.AnError
+	}
+	return x.MemoryFixtures.Promote(id, gameId)
+}
+
+func TestScheduler_PromoteDue_Interrupted(t *testing.T) {
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := &unkickableBoard{ScoreBoard: NewScoreBoard(), fail: true}
+	fixtures := &unpromotableFixtures{MemoryFixtures: NewMemoryFixtures(), fail: true}
+	scheduler := NewScheduler(fixtures, board, clock)
+
+	_, err := scheduler.AddFixture("Spain", "Brazil", start, "")
+	assert.NoError(t, err)
+
+	// The game is scheduled, but stopping before the kickoff leaves it waiting on the board.
+	_, err = scheduler.PromoteDue()
+	assert.ErrorIs(t, err, assert.AnError)
+	games := board.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, models.StatusScheduled, games[0].Status)
+
+	// The waiting game is kicked off, but stopping before the promotion leaves the fixture upcoming.
+	board.fail = false
+	clock.Advance(time.Minute)
+	_, err = scheduler.PromoteDue()
+	assert.ErrorIs(t, err, assert.AnError)
+	games = board.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, models.StatusLive, games[0].Status)
+	assert.Equal(t, true, start.Add(time.Minute).Equal(games[0].StartedAt))
+	upcoming, err := scheduler.Upcoming()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(upcoming))
+	assert.Equal(t, true, upcoming[0].Promoting)
+
+	// The live game is promoted instead of starting the fixture a second time.
+	fixtures.fail = false
+	clock.Advance(time.Minute)
+	next, err := scheduler.PromoteDue()
+	assert.NoError(t, err)
+	assert.Equal(t, true, next.IsZero())
+	games = board.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, true, start.Add(time.Minute).Equal(games[0].StartedAt))
+	fixture, err := scheduler.Promoted(games[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, upcoming[0].Id, fixture.Id)
+}
+
+func TestScheduler_PromoteDue_Rematch(t *testing.T) {
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+
+	first, err := scheduler.AddFixture("Spain", "Brazil", start, "")
+	assert.NoError(t, err)
+	rematch, err := scheduler.AddFixture("Spain", "Brazil", start, "")
+	assert.NoError(t, err)
+
+	// The rematch waits for the first game instead of being promoted to it.
+	_, err = scheduler.PromoteDue()
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	games := board.GetGames()
+	assert.Equal(t, 1, len(games))
+	fixture, err := scheduler.Promoted(games[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Id, fixture.Id)
+
+	_, err = board.RemoveGame(games[0].Id)
+	assert.NoError(t, err)
+	_, err = scheduler.PromoteDue()
+	assert.NoError(t, err)
+	games = board.GetGames()
+	assert.Equal(t, 1, len(games))
+	fixture, err = scheduler.Promoted(games[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, rematch.Id, fixture.Id)
+}
+
+func TestScheduler_Run(t *testing.T) {
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx)
+
+	// A fixture added while the scheduler waits is picked up without a restart.
+	_, err := scheduler.AddFixture("Spain", "Brazil", start.Add(time.Hour), "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Germany", "France", start.Add(-time.Minute), "")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(board.GetGames()) == 1
+	}, time.Second, time.Millisecond)
+
+	clock.Advance(time.Hour)
+	assert.Eventually(t, func() bool {
+		return len(board.GetGames()) == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestScheduler_PromoteDue_TeamAlreadyPlaying(t *testing.T) {
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+
+	_, err := scheduler.AddFixture("Spain", "Brazil", start, "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Germany", "France", start, "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Italy", "Japan", start.Add(time.Hour), "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Egypt", "Egypt", start, "")
+	assert.ErrorIs(t, err, models.ErrSameTeam)
+	playing, err := board.StartGame("Brazil", "USA")
+	assert.NoError(t, err)
+
+	// The fixture of the busy team waits, the fixtures after it are promoted anyway.
+	next, err := scheduler.PromoteDue()
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	assert.Equal(t, start.Add(time.Hour), next)
+	assert.Equal(t, 2, len(board.GetGames()))
+
+	_, err = board.RemoveGame(playing.Id)
+	assert.NoError(t, err)
+	next, err = scheduler.PromoteDue()
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), next)
+	assert.Equal(t, 2, len(board.GetGames()))
+	upcoming, err := scheduler.Upcoming()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(upcoming))
+}
+
+func TestScheduler_PromoteDue_RetiredTeam(t *testing.T) {
+	registry := useTeams(t, models.Team{Id: "Ajax"}, models.Team{Id: "Celtic"}, models.Team{Id: "Rangers"}, models.Team{Id: "Hearts"})
+	start := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, clock)
+
+	_, err := scheduler.AddFixture("Ajax", "Celtic", start, "")
+	assert.NoError(t, err)
+	_, err = scheduler.AddFixture("Rangers", "Hearts", start, "")
+	assert.NoError(t, err)
+	scheduled, err := scheduler.Scheduled("Celtic")
+	assert.NoError(t, err)
+	assert.True(t, scheduled)
+	_, err = registry.RetireTeam("Celtic")
+	assert.NoError(t, err)
+
+	// The fixture of the retired team waits, the fixtures after it are promoted anyway.
+	next, err := scheduler.PromoteDue()
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+	assert.True(t, next.IsZero())
+	assert.Equal(t, 1, len(board.GetGames()))
+	assert.Equal(t, models.Countries("Rangers"), board.GetGames()[0].HomeTeam)
+}
+
+func TestApi_RetireTeam_Scheduled(t *testing.T) {
+	registry := useTeams(t, models.Team{Id: "Ajax"}, models.Team{Id: "Celtic"}, models.Team{Id: "Rangers"})
+	board := NewScoreBoard()
+	scheduler := NewScheduler(NewMemoryFixtures(), board, newFakeClock(time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)))
+	app := NewApp(NewScoreBase(), board, WithScheduler(scheduler), WithTeams(registry))
+	app.InitRoutes()
+
+	_, err := scheduler.AddFixture("Ajax", "Celtic", time.Date(2026, 6, 12, 18, 0, 0, 0, time.UTC), "")
+	assert.NoError(t, err)
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/teams/Celtic/retire", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = doRequest(app, http.MethodPost, "/api/v1/teams/Rangers/retire", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -2,8 +2,10 @@ package internal
 
 import (
 	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // ScoreBoard represents a scoreboard containing games using a concurrent-safe map.
@@ -12,25 +14,54 @@ import (
 // 2. Deleting an element, the complexity of this action in map is O(1)
 // 3. Updating an element, the complexity of this action in the map is O(1)
 // 4. Retrieving all elements, the complexity of this action in map is O(N)
-// From this it was concluded that it was well suited for storing active matches
+// From this it was concluded that it was well suited for storing active matches.
+// Retrieved games are sorted in the order defined by the GameComparator of the ScoreBoard.
+// A team plays in a single game on the board at a time, teams keeps the id of the game each team is playing in.
 type ScoreBoard struct {
 	Games  *sync.Map
 	nextId uint32
+	order  GameComparator
+	teams  sync.Map
 }
 
-// NewScoreBoard initializes a new scoreboard with games stored in a concurrent-safe map.
+// NewScoreBoard initializes a new scoreboard with games stored in a concurrent-safe map and the ByScoreThenRecency ordering.
 func NewScoreBoard() *ScoreBoard {
+	return NewScoreBoardWithOrder(ByScoreThenRecency)
+}
+
+// NewScoreBoardWithOrder initializes a new scoreboard retrieving games in the order defined by the provided comparator.
+func NewScoreBoardWithOrder(order GameComparator) *ScoreBoard {
 	return &ScoreBoard{
 		Games: &sync.Map{},
+		order: order,
 	}
 }
 
 // GameBoard defines methods for managing games on a game board.
-// It allows starting a game, removing a game, updating scores, and getting all games.
+// A team can only play in one game on the board, until that game is removed; adding another game with the team
+// returns models.ErrTeamAlreadyPlaying, and a game of a team against itself models.ErrSameTeam.
+// It allows starting or scheduling a game, removing a game, updating scores, recording goals, moving a game through
+// its lifecycle, and getting a game by id or all games. GetGame returns models.ErrGameNotFound for a game not on the board.
+// StartGame puts a game on the board that has kicked off already, ScheduleGame one that waits for the kickoff transition.
+// KickoffGame moves a scheduled game through models.TransitionKickoff like TransitionGame, starting it at the provided time.
+// RecordGoal adds the goal to the timeline of the game and increments its score accordingly.
+// UpdateShootout sets the penalty shootout score of a game in models.StatusPenalties.
+// Scores are only changed in the statuses accepting them, otherwise models.ErrScoreUpdateNotAllowed is returned.
+// RestoreGame puts a removed game back on the board as it is, e.g. when storing it as finished failed.
+// CompareAndSwapScore sets the score like UpdateGame, but only if the game still has the old score, otherwise
+// models.ErrScoreChanged is returned, so a score read earlier never overwrites e.g. a goal recorded since.
 type GameBoard interface {
-	StartGame(homeTeam, awayTeam string) error
+	StartGame(homeTeam, awayTeam string) (*models.Game, error)
+	ScheduleGame(homeTeam, awayTeam string) (*models.Game, error)
+	TransitionGame(id uint32, transition models.Transition) (*models.Game, error)
+	KickoffGame(id uint32, at time.Time) (*models.Game, error)
 	RemoveGame(id uint32) (*models.Game, error)
-	UpdateGame(id uint32, homeScore, awayScore uint) error
+	RestoreGame(game *models.Game) error
+	UpdateGame(id uint32, homeScore, awayScore uint) (*models.Game, error)
+	CompareAndSwapScore(id uint32, old models.Score, homeScore, awayScore uint) (*models.Game, error)
+	RecordGoal(id uint32, goal models.Goal) (*models.Game, error)
+	UpdateShootout(id uint32, homePenalties, awayPenalties uint) (*models.Game, error)
+	GetGame(id uint32) (*models.Game, error)
 	GetGames() []*models.Game
 }
 
@@ -41,27 +72,52 @@ const (
 	beginAwayScore = 0
 )
 
-// StartGame initializes a new game with the provided home and away teams, assigns initial scores, and increments the game ID.
-func (x *ScoreBoard) StartGame(homeTeam, awayTeam string) error {
+// StartGame initializes a new live game with the provided home and away teams, assigns initial scores and the start time,
+// and increments the game ID. It returns the started game.
+func (x *ScoreBoard) StartGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusLive)
+}
+
+// ScheduleGame initializes a new scheduled game the same way as StartGame. It returns the scheduled game.
+func (x *ScoreBoard) ScheduleGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusScheduled)
+}
+
+// addGame initializes a new game with the provided teams and status and stores it in the scoreboard.
+func (x *ScoreBoard) addGame(homeTeam, awayTeam string, status models.Status) (*models.Game, error) {
+	game, err := x.newGame(homeTeam, awayTeam, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.claimTeams(game.Id, game.HomeTeam, game.AwayTeam); err != nil {
+		return nil, err
+	}
+	x.Games.Store(game.Id, game)
+
+	return game, nil
+}
+
+// newGame initializes a new game with the provided teams and status and the next id, without storing it.
+func (x *ScoreBoard) newGame(homeTeam, awayTeam string, status models.Status) (*models.Game, error) {
 	id := atomic.AddUint32(&x.nextId, 1)
-	homeTeamCountry := models.GetCountryFromString(homeTeam)
-	if homeTeamCountry == models.NotACountry {
-		return models.ErrInvalidCountry
+	homeTeamCountry, err := models.ResolveTeam(homeTeam)
+	if err != nil {
+		return nil, err
 	}
-	awayTeamCountry := models.GetCountryFromString(awayTeam)
-	if awayTeamCountry == models.NotACountry {
-		return models.ErrInvalidCountry
+	awayTeamCountry, err := models.ResolveTeam(awayTeam)
+	if err != nil {
+		return nil, err
 	}
 
-	x.Games.Store(id, &models.Game{
+	return &models.Game{
 		Id:        id,
 		HomeTeam:  homeTeamCountry,
 		AwayTeam:  awayTeamCountry,
 		HomeScore: beginHomeScore,
 		AwayScore: beginAwayScore,
-	})
-
-	return nil
+		StartedAt: time.Now(),
+		Status:    status,
+	}, nil
 }
 
 // RemoveGame removes a game from the scoreboard by the provided ID, returning the removed game.
@@ -70,32 +126,158 @@ func (x *ScoreBoard) RemoveGame(id uint32) (*models.Game, error) {
 	if !ok {
 		return nil, models.ErrGameNotFound
 	}
-	return game.(*models.Game), nil
+	removed := game.(*models.Game)
+	x.releaseTeams(removed)
+	return removed, nil
 }
 
-// UpdateGame finds the game with the provided ID in the scoreboard, sets the home and away scores
-// for the game, and swaps the updated game back into the scoreboard.
-func (x *ScoreBoard) UpdateGame(id uint32, homeScore, awayScore uint) error {
-	gameMap, ok := x.Games.Load(id)
-	if !ok {
-		return models.ErrGameNotFound
+// RestoreGame puts the removed game back on the scoreboard with its id. It returns models.ErrTeamAlreadyPlaying
+// if one of its teams started another game since it was removed.
+func (x *ScoreBoard) RestoreGame(game *models.Game) error {
+	if err := x.claimTeams(game.Id, game.HomeTeam, game.AwayTeam); err != nil {
+		return err
 	}
+	x.Games.Store(game.Id, game)
+	return nil
+}
 
-	game := gameMap.(*models.Game)
+// claimTeams reserves both teams for the game with the provided id. The reservation of each team is a single
+// atomic operation, so of concurrent games with the same team only one succeeds. If the away team cannot be
+// reserved, the reservation of the home team is released again.
+func (x *ScoreBoard) claimTeams(id uint32, homeTeam, awayTeam models.Countries) error {
+	if homeTeam == awayTeam {
+		return models.ErrSameTeam
+	}
+	if _, playing := x.teams.LoadOrStore(homeTeam, id); playing {
+		return models.ErrTeamAlreadyPlaying
+	}
+	if _, playing := x.teams.LoadOrStore(awayTeam, id); playing {
+		x.teams.CompareAndDelete(homeTeam, id)
+		return models.ErrTeamAlreadyPlaying
+	}
+	return nil
+}
 
-	game.SetHomeScore(homeScore).SetAwayScore(awayScore)
+// releaseTeams releases the teams of the game, so they can play in another game.
+func (x *ScoreBoard) releaseTeams(game *models.Game) {
+	x.teams.CompareAndDelete(game.HomeTeam, game.Id)
+	x.teams.CompareAndDelete(game.AwayTeam, game.Id)
+}
 
-	x.Games.Swap(id, game)
+// UpdateGame sets the home and away scores on a copy of the game with the provided ID and swaps the copy
+// into the scoreboard, so games returned earlier are never changed by an update. It returns the updated game.
+func (x *ScoreBoard) UpdateGame(id uint32, homeScore, awayScore uint) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return game.SetScore(homeScore, awayScore)
+	})
+}
 
-	return nil
+// CompareAndSwapScore sets the home and away scores on a copy of the game with the provided ID the same way as UpdateGame,
+// if the game still has the old score. It returns the updated game.
+func (x *ScoreBoard) CompareAndSwapScore(id uint32, old models.Score, homeScore, awayScore uint) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return setScoreFrom(game, old, homeScore, awayScore)
+	})
 }
 
-// GetGames retrieves all games stored in the scoreboard and returns them as a slice of Game pointers.
+// setScoreFrom sets the score of the game if it still has the old score, otherwise models.ErrScoreChanged is returned.
+func setScoreFrom(game *models.Game, old models.Score, homeScore, awayScore uint) error {
+	if game.HomeScore != old.Home || game.AwayScore != old.Away {
+		return models.ErrScoreChanged
+	}
+	return game.SetScore(homeScore, awayScore)
+}
+
+// RecordGoal adds the goal to a copy of the game with the provided ID and swaps the copy into the scoreboard,
+// so games returned earlier are never changed by a goal. It returns the updated game.
+func (x *ScoreBoard) RecordGoal(id uint32, goal models.Goal) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return game.AddGoal(goal)
+	})
+}
+
+// UpdateShootout sets the penalty shootout score on a copy of the game with the provided ID and swaps the copy
+// into the scoreboard. It returns the updated game.
+func (x *ScoreBoard) UpdateShootout(id uint32, homePenalties, awayPenalties uint) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return game.SetPenaltyScore(homePenalties, awayPenalties)
+	})
+}
+
+// TransitionGame moves a copy of the game with the provided ID through the transition and swaps the copy
+// into the scoreboard. It returns the updated game.
+func (x *ScoreBoard) TransitionGame(id uint32, transition models.Transition) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return game.Transition(transition)
+	})
+}
+
+// KickoffGame kicks off a copy of the scheduled game with the provided ID at the provided time and swaps the copy
+// into the scoreboard. It returns the updated game.
+func (x *ScoreBoard) KickoffGame(id uint32, at time.Time) (*models.Game, error) {
+	return x.swapGame(id, func(game *models.Game) error {
+		return game.Kickoff(at)
+	})
+}
+
+// swapGame applies the change to a copy of the game with the provided ID and swaps the copy into the scoreboard.
+// If the game was changed concurrently, the change is applied again to the new state.
+func (x *ScoreBoard) swapGame(id uint32, change func(game *models.Game) error) (*models.Game, error) {
+	for {
+		current, ok := x.Games.Load(id)
+		if !ok {
+			return nil, models.ErrGameNotFound
+		}
+
+		updated := *current.(*models.Game)
+		if err := change(&updated); err != nil {
+			return nil, err
+		}
+		if x.Games.CompareAndSwap(id, current, &updated) {
+			return &updated, nil
+		}
+	}
+}
+
+// GetGame retrieves the game with the provided id from the scoreboard.
+func (x *ScoreBoard) GetGame(id uint32) (*models.Game, error) {
+	game, ok := x.Games.Load(id)
+	if !ok {
+		return nil, models.ErrGameNotFound
+	}
+	return game.(*models.Game), nil
+}
+
+// GetGames retrieves all games stored in the scoreboard and returns them as a slice of Game pointers
+// sorted in the order of the scoreboard.
 func (x *ScoreBoard) GetGames() []*models.Game {
 	var result []*models.Game
 	x.Games.Range(func(key, value interface{}) bool {
 		result = append(result, value.(*models.Game))
 		return true
 	})
+	slices.SortFunc(result, x.order)
 	return result
 }
+
+// restore stores the game with its id as it is, making sure the id is never assigned to a new game.
+// It is used to rebuild the scoreboard from persisted state. Games persisted before statuses were introduced are live.
+func (x *ScoreBoard) restore(game *models.Game) {
+	if game.Status == "" {
+		game.Status = models.StatusLive
+	}
+	x.Games.Store(game.Id, game)
+	x.teams.Store(game.HomeTeam, game.Id)
+	x.teams.Store(game.AwayTeam, game.Id)
+	x.reserveIds(game.Id)
+}
+
+// reserveIds makes sure that ids up to and including the provided one are never assigned to a new game.
+func (x *ScoreBoard) reserveIds(id uint32) {
+	for {
+		current := atomic.LoadUint32(&x.nextId)
+		if current >= id || atomic.CompareAndSwapUint32(&x.nextId, current, id) {
+			return
+		}
+	}
+}
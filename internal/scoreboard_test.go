@@ -5,7 +5,9 @@ import (
 	"github.com/Marian2701/CodingExercise/internal/models"
 	"github.com/stretchr/testify/assert"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func getNumOfGames(scoreboard *ScoreBoard) int {
@@ -45,7 +47,7 @@ func TestScoreBoard_StartGame(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			numOfGamesBefore := getNumOfGames(scoreboard)
-			err := scoreboard.StartGame(tt.homeTeam, tt.awayTeam)
+			_, err := scoreboard.StartGame(tt.homeTeam, tt.awayTeam)
 			assert.NoError(t, err)
 			numOfGamesAfter := getNumOfGames(scoreboard)
 			if numOfGamesAfter != numOfGamesBefore+1 {
@@ -77,7 +79,7 @@ func TestScoreBoard_StartGame_WrongCountries(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := scoreboard.StartGame(tt.homeTeam, tt.awayTeam)
+			_, err := scoreboard.StartGame(tt.homeTeam, tt.awayTeam)
 			assert.ErrorIs(t, err, models.ErrInvalidCountry)
 		})
 	}
@@ -86,21 +88,55 @@ func TestScoreBoard_StartGame_WrongCountries(t *testing.T) {
 func TestScoreBoard_StartGame_concurrently(t *testing.T) {
 	scoreboard := NewScoreBoard()
 	numOfGoroutines := 1000
+	var started atomic.Int32
 	var wg sync.WaitGroup
 	wg.Add(numOfGoroutines)
 	for i := 0; i < numOfGoroutines; i++ {
 		go func() {
 			defer wg.Done()
-			err := scoreboard.StartGame("Australia", "Poland")
-			assert.NoError(t, err)
+			// Every other start shares only one of the teams, which must be rejected as well.
+			awayTeam := "Poland"
+			if i%2 == 1 {
+				awayTeam = "Japan"
+			}
+			_, err := scoreboard.StartGame("Australia", awayTeam)
+			if err == nil {
+				started.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
 		}()
 	}
 	wg.Wait()
-	if numOfGames := getNumOfGames(scoreboard); numOfGames != numOfGoroutines {
-		t.Errorf("number of games after starting new game concurrently should be %v, got: %v", numOfGoroutines, numOfGames)
+	assert.Equal(t, int32(1), started.Load())
+	if numOfGames := getNumOfGames(scoreboard); numOfGames != 1 {
+		t.Errorf("number of games after starting the same team concurrently should be 1, got: %v", numOfGames)
 	}
 }
 
+func TestScoreBoard_StartGame_TeamAlreadyPlaying(t *testing.T) {
+	scoreboard := NewScoreBoard()
+
+	_, err := scoreboard.StartGame("Brazil", "Brazil")
+	assert.ErrorIs(t, err, models.ErrSameTeam)
+
+	game, err := scoreboard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = scoreboard.ScheduleGame("Brazil", "Germany")
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	_, err = scoreboard.StartGame("Germany", "Spain")
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+
+	// A rejected game must not keep its other team busy.
+	_, err = scoreboard.StartGame("Germany", "France")
+	assert.NoError(t, err)
+
+	_, err = scoreboard.RemoveGame(game.Id)
+	assert.NoError(t, err)
+	_, err = scoreboard.StartGame("Brazil", "Spain")
+	assert.NoError(t, err)
+}
+
 func TestScoreBoard_RemoveGame(t *testing.T) {
 	tests := []struct {
 		name string
@@ -142,7 +178,7 @@ func TestScoreBoard_RemoveGame(t *testing.T) {
 	scoreboard := NewScoreBoard()
 
 	for _, datum := range testData {
-		err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		_, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
 		assert.NoError(t, err)
 	}
 
@@ -183,7 +219,7 @@ func TestScoreBoard_RemoveGame_WrongId(t *testing.T) {
 	scoreboard := NewScoreBoard()
 
 	for _, datum := range testData {
-		err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		_, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
 		assert.NoError(t, err)
 	}
 
@@ -215,7 +251,7 @@ func TestScoreBoard_GetGames(t *testing.T) {
 	scoreboard := NewScoreBoard()
 
 	for i, datum := range testData {
-		err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		_, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
 		assert.NoError(t, err)
 		assert.Equal(t, i+1, getNumOfGames(scoreboard))
 	}
@@ -223,6 +259,24 @@ func TestScoreBoard_GetGames(t *testing.T) {
 	assert.Equal(t, getNumOfGames(scoreboard), len(scoreboard.GetGames()))
 }
 
+func TestScoreBoard_GetGame(t *testing.T) {
+	scoreboard := NewScoreBoard()
+	started, err := scoreboard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	game, err := scoreboard.GetGame(started.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, started, game)
+
+	_, err = scoreboard.GetGame(started.Id + 1)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	_, err = scoreboard.RemoveGame(started.Id)
+	assert.NoError(t, err)
+	_, err = scoreboard.GetGame(started.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+}
+
 func TestScoreBoard_UpdateGame(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -277,13 +331,13 @@ func TestScoreBoard_UpdateGame(t *testing.T) {
 	scoreboard := NewScoreBoard()
 
 	for _, datum := range testData {
-		err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		_, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
 		assert.NoError(t, err)
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := scoreboard.UpdateGame(tt.id, tt.newValueHome, tt.newValueAway)
+			_, err := scoreboard.UpdateGame(tt.id, tt.newValueHome, tt.newValueAway)
 			assert.NoError(t, err)
 			games := scoreboard.GetGames()
 			g := &models.Game{}
@@ -298,6 +352,35 @@ func TestScoreBoard_UpdateGame(t *testing.T) {
 	}
 }
 
+func TestScoreBoard_UpdateGame_concurrently(t *testing.T) {
+	scoreboard := NewScoreBoard()
+	game, err := scoreboard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	numOfGoals := 100
+	var wg sync.WaitGroup
+	wg.Add(2 * numOfGoals)
+	for i := 0; i < numOfGoals; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := scoreboard.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := scoreboard.UpdateGame(game.Id, 1, 0)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// No goal is lost to a concurrent update, and the game returned by StartGame is never changed.
+	updated, err := scoreboard.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, numOfGoals, len(updated.Goals))
+	assert.Equal(t, uint(0), game.HomeScore)
+}
+
 func TestScoreBoard_UpdateGame_WrongId(t *testing.T) {
 	tests := []struct {
 		name string
@@ -320,14 +403,387 @@ func TestScoreBoard_UpdateGame_WrongId(t *testing.T) {
 	scoreboard := NewScoreBoard()
 
 	for _, datum := range testData {
-		err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		_, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
 		assert.NoError(t, err)
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := scoreboard.UpdateGame(tt.id, 0, 0)
+			_, err := scoreboard.UpdateGame(tt.id, 0, 0)
 			assert.ErrorIs(t, err, models.ErrGameNotFound)
 		})
 	}
 }
+
+func TestScoreBoard_GetGames_Ordering(t *testing.T) {
+	testData := []struct {
+		HomeTeam  string
+		AwayTeam  string
+		HomeScore uint
+		AwayScore uint
+	}{
+		{HomeTeam: "USA", AwayTeam: "Italy", HomeScore: 1, AwayScore: 1},
+		{HomeTeam: "Spain", AwayTeam: "Brazil", HomeScore: 3, AwayScore: 0},
+		{HomeTeam: "Morocco", AwayTeam: "Canada", HomeScore: 0, AwayScore: 0},
+		{HomeTeam: "Argentina", AwayTeam: "Australia", HomeScore: 0, AwayScore: 2},
+		{HomeTeam: "Germany", AwayTeam: "France", HomeScore: 0, AwayScore: 0},
+	}
+
+	scoreboard := NewScoreBoard()
+
+	for _, datum := range testData {
+		game, err := scoreboard.StartGame(datum.HomeTeam, datum.AwayTeam)
+		assert.NoError(t, err)
+		_, err = scoreboard.UpdateGame(game.Id, datum.HomeScore, datum.AwayScore)
+		assert.NoError(t, err)
+	}
+
+	var gotIds []uint32
+	for _, game := range scoreboard.GetGames() {
+		gotIds = append(gotIds, game.Id)
+	}
+	assert.Equal(t, []uint32{2, 4, 1, 5, 3}, gotIds)
+}
+
+func TestScoreBoard_RecordGoal(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        uint32
+		goal      models.Goal
+		wantErr   error
+		wantHome  uint
+		wantAway  uint
+		wantGoals []uint
+	}{
+		{
+			name:      "Home goal",
+			id:        1,
+			goal:      models.Goal{Minute: 30, Team: models.Spain, Player: "Morata", Type: models.GoalRegular},
+			wantHome:  1,
+			wantGoals: []uint{30},
+		},
+		{
+			name:      "Own goal counts for the other team",
+			id:        1,
+			goal:      models.Goal{Minute: 75, Team: models.Brazil, Player: "Ramos", Type: models.GoalOwn},
+			wantHome:  1,
+			wantAway:  1,
+			wantGoals: []uint{30, 75},
+		},
+		{
+			name:      "Late recorded goal is ordered by minute",
+			id:        1,
+			goal:      models.Goal{Minute: 12, Team: models.Spain, Player: "Morata", Type: models.GoalPenalty},
+			wantHome:  2,
+			wantAway:  1,
+			wantGoals: []uint{12, 30, 75},
+		},
+		{
+			name:    "Team not playing",
+			id:      1,
+			goal:    models.Goal{Minute: 80, Team: models.Italy, Player: "Rossi", Type: models.GoalRegular},
+			wantErr: models.ErrInvalidGoal,
+		},
+		{
+			name:    "Wrong minute",
+			id:      1,
+			goal:    models.Goal{Minute: 0, Team: models.Spain, Player: "Morata", Type: models.GoalRegular},
+			wantErr: models.ErrInvalidGoal,
+		},
+		{
+			name:    "Wrong type",
+			id:      1,
+			goal:    models.Goal{Minute: 80, Team: models.Spain, Player: "Morata", Type: "header"},
+			wantErr: models.ErrInvalidGoal,
+		},
+		{
+			name:    "Missing player",
+			id:      1,
+			goal:    models.Goal{Minute: 80, Team: models.Spain, Player: " ", Type: models.GoalRegular},
+			wantErr: models.ErrInvalidGoal,
+		},
+		{
+			name:    "Wrong id",
+			id:      99,
+			goal:    models.Goal{Minute: 80, Team: models.Spain, Player: "Morata", Type: models.GoalRegular},
+			wantErr: models.ErrGameNotFound,
+		},
+	}
+
+	scoreboard := NewScoreBoard()
+	started, err := scoreboard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := scoreboard.RecordGoal(tt.id, tt.goal)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHome, game.HomeScore)
+			assert.Equal(t, tt.wantAway, game.AwayScore)
+			var minutes []uint
+			for _, goal := range game.Goals {
+				minutes = append(minutes, goal.Minute)
+			}
+			assert.Equal(t, tt.wantGoals, minutes)
+		})
+	}
+
+	// Goals are recorded on copies, so the game returned by StartGame is unchanged.
+	assert.Equal(t, 0, len(started.Goals))
+	assert.Equal(t, uint(0), started.HomeScore)
+}
+
+func TestScoreBoard_TransitionGame(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    bool
+		transitions []models.Transition
+		wantStatus  models.Status
+		wantErr     error
+	}{
+		{
+			name:        "Kickoff of a scheduled game",
+			schedule:    true,
+			transitions: []models.Transition{models.TransitionKickoff},
+			wantStatus:  models.StatusLive,
+		},
+		{
+			name:        "Half-time and resume",
+			transitions: []models.Transition{models.TransitionHalfTime, models.TransitionResume},
+			wantStatus:  models.StatusLive,
+		},
+		{
+			name:        "Extra time and shootout",
+			transitions: []models.Transition{models.TransitionExtraTime, models.TransitionShootout},
+			wantStatus:  models.StatusPenalties,
+		},
+		{
+			name:        "Abandon at half-time",
+			transitions: []models.Transition{models.TransitionHalfTime, models.TransitionAbandon},
+			wantStatus:  models.StatusAbandoned,
+		},
+		{
+			name:        "Kickoff of a live game",
+			transitions: []models.Transition{models.TransitionKickoff},
+			wantStatus:  models.StatusLive,
+			wantErr:     models.ErrInvalidTransition,
+		},
+		{
+			name:        "Extra time at half-time",
+			transitions: []models.Transition{models.TransitionHalfTime, models.TransitionExtraTime},
+			wantStatus:  models.StatusHalfTime,
+			wantErr:     models.ErrInvalidTransition,
+		},
+		{
+			name:        "Resume an abandoned game",
+			transitions: []models.Transition{models.TransitionAbandon, models.TransitionResume},
+			wantStatus:  models.StatusAbandoned,
+			wantErr:     models.ErrInvalidTransition,
+		},
+		{
+			name:        "Unknown transition",
+			transitions: []models.Transition{"timeout"},
+			wantStatus:  models.StatusLive,
+			wantErr:     models.ErrUnknownTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoreboard := NewScoreBoard()
+			add := scoreboard.StartGame
+			if tt.schedule {
+				add = scoreboard.ScheduleGame
+			}
+			game, err := add("Spain", "Brazil")
+			assert.NoError(t, err)
+
+			for _, transition := range tt.transitions {
+				_, err = scoreboard.TransitionGame(game.Id, transition)
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantStatus, scoreboard.GetGames()[0].Status)
+		})
+	}
+
+	_, err := NewScoreBoard().TransitionGame(99, models.TransitionKickoff)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+}
+
+func TestScoreBoard_KickoffGame(t *testing.T) {
+	kickoff := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	scoreboard := NewScoreBoard()
+	scheduled, err := scoreboard.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	game, err := scoreboard.KickoffGame(scheduled.Id, kickoff)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusLive, game.Status)
+	assert.Equal(t, kickoff, game.StartedAt)
+	assert.NotEqual(t, kickoff, scheduled.StartedAt)
+	_, err = scoreboard.KickoffGame(scheduled.Id, kickoff)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	_, err = scoreboard.KickoffGame(99, kickoff)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+}
+
+func TestScoreBoard_ScoreUpdatesByStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    bool
+		transitions []models.Transition
+		wantUpdate  error
+		wantGoal    error
+	}{
+		{
+			name: "Live",
+		},
+		{
+			name:       "Scheduled",
+			schedule:   true,
+			wantUpdate: models.ErrScoreUpdateNotAllowed,
+			wantGoal:   models.ErrScoreUpdateNotAllowed,
+		},
+		{
+			name:        "Half-time",
+			transitions: []models.Transition{models.TransitionHalfTime},
+			wantGoal:    models.ErrScoreUpdateNotAllowed,
+		},
+		{
+			name:        "Penalties",
+			transitions: []models.Transition{models.TransitionShootout},
+			wantUpdate:  models.ErrScoreUpdateNotAllowed,
+			wantGoal:    models.ErrScoreUpdateNotAllowed,
+		},
+		{
+			name:        "Abandoned",
+			transitions: []models.Transition{models.TransitionAbandon},
+			wantUpdate:  models.ErrScoreUpdateNotAllowed,
+			wantGoal:    models.ErrScoreUpdateNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoreboard := NewScoreBoard()
+			add := scoreboard.StartGame
+			if tt.schedule {
+				add = scoreboard.ScheduleGame
+			}
+			game, err := add("Spain", "Brazil")
+			assert.NoError(t, err)
+			for _, transition := range tt.transitions {
+				_, err := scoreboard.TransitionGame(game.Id, transition)
+				assert.NoError(t, err)
+			}
+
+			_, err = scoreboard.UpdateGame(game.Id, 1, 0)
+			assert.ErrorIs(t, err, tt.wantUpdate)
+			_, err = scoreboard.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+			assert.ErrorIs(t, err, tt.wantGoal)
+		})
+	}
+}
+
+func TestScoreBoard_Knockout(t *testing.T) {
+	scoreboard := NewScoreBoard()
+	store := NewScoreBase()
+	finisher := NewFinishService(scoreboard, store)
+	game, err := scoreboard.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	_, err = scoreboard.RecordGoal(game.Id, models.Goal{Minute: 30, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+	assert.NoError(t, err)
+	_, err = scoreboard.TransitionGame(game.Id, models.TransitionExtraTime)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	_, err = scoreboard.RecordGoal(game.Id, models.Goal{Minute: 80, Team: models.Brazil, Player: "Neymar", Type: models.GoalRegular})
+	assert.NoError(t, err)
+
+	_, err = scoreboard.TransitionGame(game.Id, models.TransitionExtraTime)
+	assert.NoError(t, err)
+	_, err = scoreboard.RecordGoal(game.Id, models.Goal{Minute: 95, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+	assert.NoError(t, err)
+	updated, err := scoreboard.RecordGoal(game.Id, models.Goal{Minute: 118, Team: models.Brazil, Player: "Neymar", Type: models.GoalPenalty})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, updated.RegulationScore)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, updated.ExtraTimeScore)
+
+	// A correction below the regulation score corrects the regulation score as well.
+	updated, err = scoreboard.UpdateGame(game.Id, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, updated.RegulationScore)
+	assert.Equal(t, &models.Score{Home: 0, Away: 1}, updated.ExtraTimeScore)
+	updated, err = scoreboard.UpdateGame(game.Id, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, updated.ExtraTimeScore)
+
+	_, err = scoreboard.UpdateShootout(game.Id, 3, 3)
+	assert.ErrorIs(t, err, models.ErrScoreUpdateNotAllowed)
+	_, err = scoreboard.TransitionGame(game.Id, models.TransitionShootout)
+	assert.NoError(t, err)
+	_, err = scoreboard.UpdateShootout(game.Id, 3, 3)
+	assert.NoError(t, err)
+	// During the shootout the score can only be corrected to another draw.
+	_, err = scoreboard.UpdateGame(game.Id, 3, 2)
+	assert.ErrorIs(t, err, models.ErrScoreUpdateNotAllowed)
+	_, err = scoreboard.UpdateGame(game.Id, 1, 1)
+	assert.NoError(t, err)
+	_, err = scoreboard.UpdateGame(game.Id, 2, 2)
+	assert.NoError(t, err)
+	_, err = finisher.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrShootoutUndecided)
+	_, err = scoreboard.UpdateShootout(game.Id, 5, 4)
+	assert.NoError(t, err)
+
+	finished, err := finisher.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Spain, finished.Winner())
+	assert.Equal(t, uint(4), finished.GetScore())
+	assert.Equal(t, &models.Score{Home: 5, Away: 4}, finished.PenaltyScore)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, finished.RegulationScore)
+}
+
+func TestGame_Winner(t *testing.T) {
+	tests := []struct {
+		name string
+		game models.Game
+		want models.Countries
+	}{
+		{
+			name: "Home win",
+			game: models.Game{HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 2, AwayScore: 1, Status: models.StatusFinished},
+			want: models.Spain,
+		},
+		{
+			name: "Draw",
+			game: models.Game{HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 1, AwayScore: 1, Status: models.StatusFinished},
+			want: models.NotACountry,
+		},
+		{
+			name: "Shootout",
+			game: models.Game{HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 1, AwayScore: 1, Status: models.StatusFinished,
+				PenaltyScore: &models.Score{Home: 2, Away: 4}},
+			want: models.Brazil,
+		},
+		{
+			name: "Not finished",
+			game: models.Game{HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 2, AwayScore: 1, Status: models.StatusLive},
+			want: models.NotACountry,
+		},
+		{
+			name: "Abandoned",
+			game: models.Game{HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 2, AwayScore: 1, Status: models.StatusAbandoned},
+			want: models.NotACountry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.game.Winner())
+		})
+	}
+}
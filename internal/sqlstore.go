@@ -0,0 +1,901 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"log"
+	_ "modernc.org/sqlite"
+	"os"
+	"strings"
+	"time"
+)
+
+// sqlMigrations defines the schema of SQLStore. Every migration is applied once, in order,
+// and the number of applied migrations is kept in the user_version pragma of the database.
+// Applied migrations must never be changed, new ones are appended to the end.
+var sqlMigrations = []string{
+	`CREATE TABLE live_games (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		home_team  TEXT    NOT NULL,
+		home_score INTEGER NOT NULL DEFAULT 0,
+		away_team  TEXT    NOT NULL,
+		away_score INTEGER NOT NULL DEFAULT 0,
+		started_at INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE finished_games (
+		seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+		id         INTEGER NOT NULL UNIQUE,
+		home_team  TEXT    NOT NULL,
+		home_score INTEGER NOT NULL,
+		away_team  TEXT    NOT NULL,
+		away_score INTEGER NOT NULL,
+		started_at INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX finished_games_summary ON finished_games ((home_score + away_score) DESC, started_at DESC, id DESC);`,
+	`CREATE TABLE goals (
+		seq     INTEGER PRIMARY KEY AUTOINCREMENT,
+		game_id INTEGER NOT NULL,
+		minute  INTEGER NOT NULL,
+		team    TEXT    NOT NULL,
+		player  TEXT    NOT NULL,
+		type    TEXT    NOT NULL
+	);
+	CREATE INDEX goals_timeline ON goals (game_id, minute, seq);`,
+	`CREATE TABLE audit_entries (
+		seq            INTEGER PRIMARY KEY AUTOINCREMENT,
+		match_id       INTEGER NOT NULL,
+		old_home_score INTEGER NOT NULL,
+		old_away_score INTEGER NOT NULL,
+		new_home_score INTEGER NOT NULL,
+		new_away_score INTEGER NOT NULL,
+		at             INTEGER NOT NULL,
+		actor          TEXT    NOT NULL,
+		reason         TEXT    NOT NULL,
+		undoes         TEXT    NOT NULL DEFAULT '[]'
+	);
+	CREATE INDEX audit_entries_match ON audit_entries (match_id, seq);`,
+	`ALTER TABLE live_games ADD COLUMN status TEXT NOT NULL DEFAULT 'live';
+	ALTER TABLE finished_games ADD COLUMN status TEXT NOT NULL DEFAULT 'finished';`,
+	`ALTER TABLE live_games ADD COLUMN regulation_home INTEGER;
+	ALTER TABLE live_games ADD COLUMN regulation_away INTEGER;
+	ALTER TABLE live_games ADD COLUMN extra_time_home INTEGER;
+	ALTER TABLE live_games ADD COLUMN extra_time_away INTEGER;
+	ALTER TABLE live_games ADD COLUMN penalty_home INTEGER;
+	ALTER TABLE live_games ADD COLUMN penalty_away INTEGER;
+	ALTER TABLE finished_games ADD COLUMN regulation_home INTEGER;
+	ALTER TABLE finished_games ADD COLUMN regulation_away INTEGER;
+	ALTER TABLE finished_games ADD COLUMN extra_time_home INTEGER;
+	ALTER TABLE finished_games ADD COLUMN extra_time_away INTEGER;
+	ALTER TABLE finished_games ADD COLUMN penalty_home INTEGER;
+	ALTER TABLE finished_games ADD COLUMN penalty_away INTEGER;`,
+	`CREATE TABLE fixtures (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		home_team  TEXT    NOT NULL,
+		away_team  TEXT    NOT NULL,
+		kickoff_at INTEGER NOT NULL,
+		venue      TEXT    NOT NULL,
+		game_id    INTEGER,
+		promoting  INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX fixtures_upcoming ON fixtures (kickoff_at, id) WHERE game_id IS NULL;
+	CREATE UNIQUE INDEX fixtures_promoted ON fixtures (game_id) WHERE game_id IS NOT NULL;`,
+}
+
+const (
+	// sqlGameColumns are the columns of a game selected from both live_games and finished_games.
+	sqlGameColumns = `id, home_team, home_score, away_team, away_score, started_at, status,
+		regulation_home, regulation_away, extra_time_home, extra_time_away, penalty_home, penalty_away`
+	// sqlGameOrder orders games the same way as ByScoreThenRecency, the default GameComparator.
+	// Custom comparators are not supported by SQLStore.
+	sqlGameOrder = `ORDER BY home_score + away_score DESC, started_at DESC, id DESC`
+	// sqlGoalsBatchSize is the maximum number of games whose goals are selected by a single query,
+	// keeping the number of bound parameters well below the limit of SQLite.
+	sqlGoalsBatchSize = 500
+)
+
+// SQLStore is a storage driver keeping active and finished games in an embedded SQLite database.
+// Goals of both active and finished games are kept in a single table keyed by the game id, since ids are never reused.
+// The GameBoard and ScoreBaseStoring implementations are returned by Board and ScoreBase,
+// because both interfaces define GetGames with a different meaning.
+type SQLStore struct {
+	db     *sql.DB
+	logger *log.Logger
+}
+
+// NewSQLStore opens the SQLite database at path, creating it if needed, and applies pending migrations.
+func NewSQLStore(path string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	// SQLite allows a single writer only, a single connection avoids busy errors between our own transactions.
+	db.SetMaxOpenConns(1)
+
+	x := &SQLStore{
+		db:     db,
+		logger: log.New(os.Stdout, "", log.LstdFlags),
+	}
+	if err := x.migrate(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+// migrate applies the migrations that were not applied to the database yet, each one in its own transaction.
+func (x *SQLStore) migrate(ctx context.Context) error {
+	var version int
+	if err := x.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+
+	for ; version < len(sqlMigrations); version++ {
+		tx, err := x.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", version+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, sqlMigrations[version]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", version+1, err)
+		}
+		// PRAGMA does not accept bound parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("update schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", version+1, err)
+		}
+	}
+	return nil
+}
+
+// Board returns the GameBoard keeping active games in the database.
+func (x *SQLStore) Board() *SQLBoard {
+	return &SQLBoard{store: x}
+}
+
+// ScoreBase returns the ScoreBaseStoring keeping finished games in the database.
+func (x *SQLStore) ScoreBase() *SQLScoreBase {
+	return &SQLScoreBase{store: x}
+}
+
+// AuditLog returns the AuditLog keeping the audit entries of score changes in the database.
+func (x *SQLStore) AuditLog() *SQLAuditLog {
+	return &SQLAuditLog{store: x}
+}
+
+// Fixtures returns the FixtureStoring keeping scheduled fixtures in the database.
+func (x *SQLStore) Fixtures() *SQLFixtures {
+	return &SQLFixtures{store: x}
+}
+
+// FinishGame moves the game with the provided id from the active games to the finished games in a single transaction,
+// so the game is never lost between removing it from the board and inserting it into the score base.
+func (x *SQLStore) FinishGame(id uint32) (*models.Game, error) {
+	ctx := context.Background()
+	tx, err := x.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin finish game: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	game, err := scanGame(tx.QueryRowContext(ctx, `DELETE FROM live_games WHERE id = ? RETURNING `+sqlGameColumns, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := game.Finish(); err != nil {
+		return nil, err
+	}
+	if err := loadGoals(ctx, tx, []*models.Game{game}); err != nil {
+		return nil, err
+	}
+	if err := insertFinishedGame(ctx, tx, game); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit finish game: %w", err)
+	}
+	return game, nil
+}
+
+// Close closes the database.
+func (x *SQLStore) Close() error {
+	return x.db.Close()
+}
+
+// SQLBoard is the GameBoard implementation of SQLStore. Ids are never reused,
+// even for games that were removed, thanks to AUTOINCREMENT.
+type SQLBoard struct {
+	store *SQLStore
+}
+
+// StartGame inserts a new live game with the provided home and away teams and initial scores.
+func (x *SQLBoard) StartGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusLive)
+}
+
+// ScheduleGame inserts a new scheduled game the same way as StartGame.
+func (x *SQLBoard) ScheduleGame(homeTeam, awayTeam string) (*models.Game, error) {
+	return x.addGame(homeTeam, awayTeam, models.StatusScheduled)
+}
+
+// addGame inserts a new game with the provided teams and status.
+func (x *SQLBoard) addGame(homeTeam, awayTeam string, status models.Status) (*models.Game, error) {
+	homeTeamCountry, err := models.ResolveTeam(homeTeam)
+	if err != nil {
+		return nil, err
+	}
+	awayTeamCountry, err := models.ResolveTeam(awayTeam)
+	if err != nil {
+		return nil, err
+	}
+
+	if homeTeamCountry == awayTeamCountry {
+		return nil, models.ErrSameTeam
+	}
+
+	ctx := context.Background()
+	tx, err := x.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin add game: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The check and the insert share the transaction, so concurrent games with the same team cannot both pass.
+	var playing int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM live_games WHERE home_team IN (?, ?) OR away_team IN (?, ?)`,
+		homeTeamCountry, awayTeamCountry, homeTeamCountry, awayTeamCountry,
+	).Scan(&playing)
+	if err != nil {
+		return nil, fmt.Errorf("query playing teams: %w", err)
+	}
+	if playing > 0 {
+		return nil, models.ErrTeamAlreadyPlaying
+	}
+
+	game, err := scanGame(tx.QueryRowContext(ctx,
+		`INSERT INTO live_games (home_team, home_score, away_team, away_score, started_at, status) VALUES (?, ?, ?, ?, ?, ?) RETURNING `+sqlGameColumns,
+		homeTeamCountry, beginHomeScore, awayTeamCountry, beginAwayScore, sqlTime(time.Now()), status,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit add game: %w", err)
+	}
+	return game, nil
+}
+
+// RemoveGame deletes the game with the provided id together with its goals and returns it.
+func (x *SQLBoard) RemoveGame(id uint32) (*models.Game, error) {
+	ctx := context.Background()
+	tx, err := x.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin remove game: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	game, err := scanGame(tx.QueryRowContext(ctx, `DELETE FROM live_games WHERE id = ? RETURNING `+sqlGameColumns, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadGoals(ctx, tx, []*models.Game{game}); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goals WHERE game_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("delete goals: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit remove game: %w", err)
+	}
+	return game, nil
+}
+
+// RestoreGame inserts the removed game back into live_games with its id and goals. It returns
+// models.ErrTeamAlreadyPlaying if one of its teams started another game since it was removed.
+func (x *SQLBoard) RestoreGame(game *models.Game) error {
+	ctx := context.Background()
+	tx, err := x.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin restore game: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var playing int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM live_games WHERE home_team IN (?, ?) OR away_team IN (?, ?)`,
+		game.HomeTeam, game.AwayTeam, game.HomeTeam, game.AwayTeam,
+	).Scan(&playing)
+	if err != nil {
+		return fmt.Errorf("query playing teams: %w", err)
+	}
+	if playing > 0 {
+		return models.ErrTeamAlreadyPlaying
+	}
+
+	if err := insertGame(ctx, tx, "live_games", game); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit restore game: %w", err)
+	}
+	return nil
+}
+
+// UpdateGame sets the home and away scores of the game with the provided id and returns the updated game.
+func (x *SQLBoard) UpdateGame(id uint32, homeScore, awayScore uint) (*models.Game, error) {
+	return x.changeGame("update score", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := game.SetScore(homeScore, awayScore); err != nil {
+			return err
+		}
+		return updateLiveGame(ctx, tx, game)
+	})
+}
+
+// CompareAndSwapScore updates the scores of the game with the provided id, if it still has the old score, in a single transaction.
+func (x *SQLBoard) CompareAndSwapScore(id uint32, old models.Score, homeScore, awayScore uint) (*models.Game, error) {
+	return x.changeGame("update score", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := setScoreFrom(game, old, homeScore, awayScore); err != nil {
+			return err
+		}
+		return updateLiveGame(ctx, tx, game)
+	})
+}
+
+// RecordGoal inserts the goal of the game with the provided id and increments its score in a single transaction.
+func (x *SQLBoard) RecordGoal(id uint32, goal models.Goal) (*models.Game, error) {
+	return x.changeGame("record goal", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := game.AddGoal(goal); err != nil {
+			return err
+		}
+		if err := updateLiveGame(ctx, tx, game); err != nil {
+			return err
+		}
+		return insertGoal(ctx, tx, id, goal)
+	})
+}
+
+// TransitionGame moves the game with the provided id through the transition and returns the updated game.
+func (x *SQLBoard) TransitionGame(id uint32, transition models.Transition) (*models.Game, error) {
+	return x.changeGame("transition game", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := game.Transition(transition); err != nil {
+			return err
+		}
+		return updateLiveGame(ctx, tx, game)
+	})
+}
+
+// KickoffGame kicks off the scheduled game with the provided id at the provided time and returns the updated game.
+func (x *SQLBoard) KickoffGame(id uint32, at time.Time) (*models.Game, error) {
+	return x.changeGame("kick off game", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := game.Kickoff(at); err != nil {
+			return err
+		}
+		return updateLiveGame(ctx, tx, game)
+	})
+}
+
+// UpdateShootout sets the penalty shootout score of the game with the provided id and returns the updated game.
+func (x *SQLBoard) UpdateShootout(id uint32, homePenalties, awayPenalties uint) (*models.Game, error) {
+	return x.changeGame("update shootout", id, func(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+		if err := game.SetPenaltyScore(homePenalties, awayPenalties); err != nil {
+			return err
+		}
+		return updateLiveGame(ctx, tx, game)
+	})
+}
+
+// changeGame selects the game with the provided id together with its goals, applies the change to it
+// and returns the changed game, all in a single transaction named by operation in errors.
+func (x *SQLBoard) changeGame(operation string, id uint32, change func(ctx context.Context, tx *sql.Tx, game *models.Game) error) (*models.Game, error) {
+	ctx := context.Background()
+	tx, err := x.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin %s: %w", operation, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	game, err := scanGame(tx.QueryRowContext(ctx, `SELECT `+sqlGameColumns+` FROM live_games WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadGoals(ctx, tx, []*models.Game{game}); err != nil {
+		return nil, err
+	}
+	if err := change(ctx, tx, game); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit %s: %w", operation, err)
+	}
+	return game, nil
+}
+
+// updateLiveGame writes the scores, the start time and the status of the game to live_games.
+func updateLiveGame(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+	args := []any{game.HomeScore, game.AwayScore, sqlTime(game.StartedAt), game.Status}
+	args = append(args, sqlScores(game)...)
+	_, err := tx.ExecContext(ctx,
+		`UPDATE live_games SET home_score = ?, away_score = ?, started_at = ?, status = ?,
+		regulation_home = ?, regulation_away = ?, extra_time_home = ?, extra_time_away = ?, penalty_home = ?, penalty_away = ?
+		WHERE id = ?`,
+		append(args, game.Id)...,
+	)
+	if err != nil {
+		return fmt.Errorf("update game: %w", err)
+	}
+	return nil
+}
+
+// GetGame returns the active game with the provided id together with its goals.
+func (x *SQLBoard) GetGame(id uint32) (*models.Game, error) {
+	return x.store.getGame(`SELECT `+sqlGameColumns+` FROM live_games WHERE id = ?`, id)
+}
+
+// GetGames returns all active games in the same order as the finished games.
+func (x *SQLBoard) GetGames() []*models.Game {
+	return x.store.getGames(`SELECT ` + sqlGameColumns + ` FROM live_games ` + sqlGameOrder)
+}
+
+// SQLScoreBase is the ScoreBaseStoring implementation of SQLStore.
+type SQLScoreBase struct {
+	store *SQLStore
+}
+
+// Insert stores the finished game together with its goals.
+func (x *SQLScoreBase) Insert(value *models.Game) error {
+	ctx := context.Background()
+	tx, err := x.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin insert finished game: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertFinishedGame(ctx, tx, value); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit insert finished game: %w", err)
+	}
+	return nil
+}
+
+// GetGame returns the finished game with the provided id together with its goals.
+func (x *SQLScoreBase) GetGame(id uint32) (*models.Game, error) {
+	return x.store.getGame(`SELECT `+sqlGameColumns+` FROM finished_games WHERE id = ?`, id)
+}
+
+// GetGames returns all finished games in the summary order, using the summary index.
+func (x *SQLScoreBase) GetGames() []*models.Game {
+	return x.store.getGames(`SELECT ` + sqlGameColumns + ` FROM finished_games ` + sqlGameOrder)
+}
+
+// Query returns a page of the finished games using the summary index.
+// The cursor game is looked up by id and the page continues with the games ordered after it.
+func (x *SQLScoreBase) Query(query SummaryQuery) (SummaryPage, error) {
+	where := []string{"1 = 1"}
+	var args []any
+	if query.After != 0 {
+		var total uint
+		var startedAt int64
+		err := x.store.db.QueryRow(
+			`SELECT home_score + away_score, started_at FROM finished_games WHERE id = ?`, query.After,
+		).Scan(&total, &startedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return SummaryPage{}, models.ErrInvalidCursor
+		}
+		if err != nil {
+			return SummaryPage{}, fmt.Errorf("read cursor game: %w", err)
+		}
+		where = append(where, `(home_score + away_score, started_at, id) < (?, ?, ?)`)
+		args = append(args, total, startedAt, query.After)
+	}
+	if query.MinScore != nil {
+		where = append(where, `home_score + away_score >= ?`)
+		args = append(args, *query.MinScore)
+	}
+	if query.MaxScore != nil {
+		where = append(where, `home_score + away_score <= ?`)
+		args = append(args, *query.MaxScore)
+	}
+	limit := ""
+	if query.Limit > 0 {
+		// One more game is selected to find out whether there is a following page.
+		limit = ` LIMIT ?`
+		args = append(args, query.Limit+1)
+	}
+
+	games, err := x.store.queryGames(
+		`SELECT `+sqlGameColumns+` FROM finished_games WHERE `+strings.Join(where, " AND ")+` `+sqlGameOrder+limit, args...,
+	)
+	if err != nil {
+		return SummaryPage{}, err
+	}
+	var page SummaryPage
+	if query.Limit > 0 && len(games) > query.Limit {
+		games = games[:query.Limit]
+		page.Next = games[len(games)-1].Id
+	}
+	page.Games = games
+	return page, nil
+}
+
+// SQLAuditLog is the AuditLog implementation of SQLStore.
+type SQLAuditLog struct {
+	store *SQLStore
+}
+
+// Append inserts the entry, the sequence number is assigned by the database.
+func (x *SQLAuditLog) Append(entry AuditEntry) (AuditEntry, error) {
+	undoes, err := json.Marshal(nonNilSeqs(entry.Undoes))
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("encode undone entries: %w", err)
+	}
+	err = x.store.db.QueryRow(
+		`INSERT INTO audit_entries (match_id, old_home_score, old_away_score, new_home_score, new_away_score, at, actor, reason, undoes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING seq`,
+		entry.MatchId, entry.OldHomeScore, entry.OldAwayScore, entry.NewHomeScore, entry.NewAwayScore,
+		sqlTime(entry.At), entry.Actor, entry.Reason, string(undoes),
+	).Scan(&entry.Seq)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("insert audit entry: %w", err)
+	}
+	return entry, nil
+}
+
+// History selects the entries of the game with the provided id in the order they were appended.
+func (x *SQLAuditLog) History(matchId uint32) ([]AuditEntry, error) {
+	rows, err := x.store.db.Query(
+		`SELECT seq, match_id, old_home_score, old_away_score, new_home_score, new_away_score, at, actor, reason, undoes
+		FROM audit_entries WHERE match_id = ? ORDER BY seq`, matchId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		var at int64
+		var undoes string
+		err := rows.Scan(&entry.Seq, &entry.MatchId, &entry.OldHomeScore, &entry.OldAwayScore,
+			&entry.NewHomeScore, &entry.NewAwayScore, &at, &entry.Actor, &entry.Reason, &undoes)
+		if err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal([]byte(undoes), &entry.Undoes); err != nil {
+			return nil, fmt.Errorf("decode undone entries: %w", err)
+		}
+		if len(entry.Undoes) == 0 {
+			entry.Undoes = nil
+		}
+		entry.At = goTime(at)
+		result = append(result, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read audit entries: %w", err)
+	}
+	return result, nil
+}
+
+// SQLFixtures is the FixtureStoring implementation of SQLStore. Promoted fixtures are kept with the id of their game.
+type SQLFixtures struct {
+	store *SQLStore
+}
+
+// AddFixture inserts a new upcoming fixture, the id is assigned by the database.
+func (x *SQLFixtures) AddFixture(homeTeam, awayTeam string, kickoffAt time.Time, venue string) (*models.Fixture, error) {
+	fixture, err := newFixture(homeTeam, awayTeam, kickoffAt, venue)
+	if err != nil {
+		return nil, err
+	}
+	err = x.store.db.QueryRow(
+		`INSERT INTO fixtures (home_team, away_team, kickoff_at, venue) VALUES (?, ?, ?, ?) RETURNING id`,
+		fixture.HomeTeam, fixture.AwayTeam, sqlTime(fixture.KickoffAt), fixture.Venue,
+	).Scan(&fixture.Id)
+	if err != nil {
+		return nil, fmt.Errorf("insert fixture: %w", err)
+	}
+	return fixture, nil
+}
+
+// Upcoming selects the fixtures that were not promoted yet ordered by kickoff time.
+func (x *SQLFixtures) Upcoming() ([]*models.Fixture, error) {
+	rows, err := x.store.db.Query(
+		`SELECT id, home_team, away_team, kickoff_at, venue, promoting FROM fixtures WHERE game_id IS NULL ORDER BY kickoff_at, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query fixtures: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*models.Fixture, 0)
+	for rows.Next() {
+		var fixture models.Fixture
+		var homeTeam, awayTeam string
+		var kickoffAt int64
+		if err := rows.Scan(&fixture.Id, &homeTeam, &awayTeam, &kickoffAt, &fixture.Venue, &fixture.Promoting); err != nil {
+			return nil, fmt.Errorf("scan fixture: %w", err)
+		}
+		fixture.HomeTeam = models.Countries(homeTeam)
+		fixture.AwayTeam = models.Countries(awayTeam)
+		fixture.KickoffAt = goTime(kickoffAt)
+		result = append(result, &fixture)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read fixtures: %w", err)
+	}
+	return result, nil
+}
+
+// BeginPromote marks the upcoming fixture as promoting.
+func (x *SQLFixtures) BeginPromote(id uint32) error {
+	return x.updateUpcoming("begin promoting fixture", `UPDATE fixtures SET promoting = 1 WHERE id = ? AND game_id IS NULL`, id)
+}
+
+// Promote stores the id of the game the upcoming fixture was promoted to.
+func (x *SQLFixtures) Promote(id, gameId uint32) error {
+	return x.updateUpcoming("promote fixture", `UPDATE fixtures SET game_id = ?, promoting = 0 WHERE id = ? AND game_id IS NULL`, gameId, id)
+}
+
+// updateUpcoming runs the update of an upcoming fixture, returning models.ErrFixtureNotFound if no upcoming fixture was updated.
+func (x *SQLFixtures) updateUpcoming(operation, query string, args ...any) error {
+	result, err := x.store.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("%s: %w", operation, err)
+	} else if affected == 0 {
+		return models.ErrFixtureNotFound
+	}
+	return nil
+}
+
+// Promoted selects the fixture promoted to the game with the provided id.
+func (x *SQLFixtures) Promoted(gameId uint32) (*models.Fixture, error) {
+	fixture := models.Fixture{GameId: gameId}
+	var homeTeam, awayTeam string
+	var kickoffAt int64
+	err := x.store.db.QueryRow(
+		`SELECT id, home_team, away_team, kickoff_at, venue FROM fixtures WHERE game_id = ?`, gameId,
+	).Scan(&fixture.Id, &homeTeam, &awayTeam, &kickoffAt, &fixture.Venue)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrFixtureNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select fixture: %w", err)
+	}
+	fixture.HomeTeam = models.Countries(homeTeam)
+	fixture.AwayTeam = models.Countries(awayTeam)
+	fixture.KickoffAt = goTime(kickoffAt)
+	return &fixture, nil
+}
+
+// nonNilSeqs makes sure an empty list of sequence numbers is encoded as an empty JSON array instead of null.
+func nonNilSeqs(seqs []uint64) []uint64 {
+	if seqs == nil {
+		return []uint64{}
+	}
+	return seqs
+}
+
+// sqlExecutor is implemented by both *sql.DB and *sql.Tx.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// insertFinishedGame inserts the game into finished_games and replaces the stored goals of the game with its timeline.
+// It must run in a transaction, so the game is never stored without its goals.
+func insertFinishedGame(ctx context.Context, tx *sql.Tx, game *models.Game) error {
+	return insertGame(ctx, tx, "finished_games", game)
+}
+
+// insertGame inserts the game with its id into the table, finished_games or live_games, and replaces the stored goals
+// of the game with its timeline. It must run in a transaction, so the game is never stored without its goals.
+func insertGame(ctx context.Context, tx *sql.Tx, table string, game *models.Game) error {
+	args := []any{game.Id, game.HomeTeam, game.HomeScore, game.AwayTeam, game.AwayScore, sqlTime(game.StartedAt), game.Status}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO `+table+` (id, home_team, home_score, away_team, away_score, started_at, status,
+		regulation_home, regulation_away, extra_time_home, extra_time_away, penalty_home, penalty_away)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append(args, sqlScores(game)...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("insert game into %s: %w", table, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM goals WHERE game_id = ?`, game.Id); err != nil {
+		return fmt.Errorf("delete goals: %w", err)
+	}
+	for _, goal := range game.Goals {
+		if err := insertGoal(ctx, tx, game.Id, goal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertGoal inserts the goal of the game with the provided id.
+func insertGoal(ctx context.Context, db sqlExecutor, id uint32, goal models.Goal) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO goals (game_id, minute, team, player, type) VALUES (?, ?, ?, ?, ?)`,
+		id, goal.Minute, goal.Team, goal.Player, goal.Type,
+	)
+	if err != nil {
+		return fmt.Errorf("insert goal: %w", err)
+	}
+	return nil
+}
+
+// loadGoals selects the goals of the games and sets them as their timelines, ordered by minute and recording order.
+func loadGoals(ctx context.Context, db sqlExecutor, games []*models.Game) error {
+	byId := make(map[uint32]*models.Game, len(games))
+	for start := 0; start < len(games); start += sqlGoalsBatchSize {
+		batch := games[start:min(start+sqlGoalsBatchSize, len(games))]
+		args := make([]any, 0, len(batch))
+		for _, game := range batch {
+			byId[game.Id] = game
+			args = append(args, game.Id)
+		}
+
+		rows, err := db.QueryContext(ctx,
+			`SELECT game_id, minute, team, player, type FROM goals WHERE game_id IN (?`+strings.Repeat(", ?", len(batch)-1)+`) ORDER BY game_id, minute, seq`,
+			args...,
+		)
+		if err != nil {
+			return fmt.Errorf("query goals: %w", err)
+		}
+		for rows.Next() {
+			var id uint32
+			var goal models.Goal
+			var team, goalType string
+			if err := rows.Scan(&id, &goal.Minute, &team, &goal.Player, &goalType); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("scan goal: %w", err)
+			}
+			goal.Team = models.Countries(team)
+			goal.Type = models.GoalType(goalType)
+			byId[id].Goals = append(byId[id].Goals, goal)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return fmt.Errorf("read goals: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryGames runs the query and returns the selected games together with their goals.
+func (x *SQLStore) queryGames(query string, args ...any) ([]*models.Game, error) {
+	result, err := x.scanGames(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadGoals(context.Background(), x.db, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanGames runs the query and returns the selected games without their goals.
+// The rows are closed before returning, so the single connection is free for selecting the goals.
+func (x *SQLStore) scanGames(query string, args ...any) ([]*models.Game, error) {
+	rows, err := x.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query games: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*models.Game
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read games: %w", err)
+	}
+	return result, nil
+}
+
+// getGame runs the query selecting a single game by id and returns the game together with its goals,
+// models.ErrGameNotFound if no game is selected.
+func (x *SQLStore) getGame(query string, id uint32) (*models.Game, error) {
+	game, err := scanGame(x.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadGoals(context.Background(), x.db, []*models.Game{game}); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// getGames runs the query and returns the selected games.
+// GetGames of the interfaces does not allow returning an error, so failures are logged and reported as no games.
+func (x *SQLStore) getGames(query string) []*models.Game {
+	games, err := x.queryGames(query)
+	if err != nil {
+		x.logger.Println("failed to get games: ", err)
+		return nil
+	}
+	return games
+}
+
+// sqlScanner is implemented by both *sql.Row and *sql.Rows.
+type sqlScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanGame scans the sqlGameColumns of a row into a game, reporting a missing row as models.ErrGameNotFound.
+func scanGame(row sqlScanner) (*models.Game, error) {
+	var game models.Game
+	var homeTeam, awayTeam, status string
+	var startedAt int64
+	var regulationHome, regulationAway, extraTimeHome, extraTimeAway, penaltyHome, penaltyAway sql.NullInt64
+	err := row.Scan(&game.Id, &homeTeam, &game.HomeScore, &awayTeam, &game.AwayScore, &startedAt, &status,
+		&regulationHome, &regulationAway, &extraTimeHome, &extraTimeAway, &penaltyHome, &penaltyAway)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, models.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan game: %w", err)
+	}
+	game.HomeTeam = models.Countries(homeTeam)
+	game.AwayTeam = models.Countries(awayTeam)
+	game.StartedAt = goTime(startedAt)
+	game.Status = models.Status(status)
+	game.RegulationScore = goScore(regulationHome, regulationAway)
+	game.ExtraTimeScore = goScore(extraTimeHome, extraTimeAway)
+	game.PenaltyScore = goScore(penaltyHome, penaltyAway)
+	return &game, nil
+}
+
+// sqlScores returns the regulation, extra-time and penalty scores of the game as the column values
+// stored in the database, a missing score is stored as NULL.
+func sqlScores(game *models.Game) []any {
+	var values []any
+	for _, score := range []*models.Score{game.RegulationScore, game.ExtraTimeScore, game.PenaltyScore} {
+		if score == nil {
+			values = append(values, nil, nil)
+		} else {
+			values = append(values, score.Home, score.Away)
+		}
+	}
+	return values
+}
+
+// goScore converts the home and away column values stored in the database to a score, NULL is read as no score.
+func goScore(home, away sql.NullInt64) *models.Score {
+	if !home.Valid || !away.Valid {
+		return nil
+	}
+	return &models.Score{Home: uint(home.Int64), Away: uint(away.Int64)}
+}
+
+// sqlTime converts the time to the Unix nanoseconds stored in the database, the zero time is stored as 0.
+func sqlTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// goTime converts the Unix nanoseconds stored in the database to the time, 0 is read as the zero time.
+func goTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,316 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSQLStore(t *testing.T) (*SQLStore, string) {
+	path := filepath.Join(t.TempDir(), "games.db")
+	store, err := NewSQLStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, path
+}
+
+func TestSQLBoard(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	board := store.Board()
+
+	_, err := board.StartGame("Norway", "Brazil")
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+
+	spain, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	usa, err := board.StartGame("USA", "Italy")
+	assert.NoError(t, err)
+	assert.Equal(t, spain.Id+1, usa.Id)
+
+	game, err := board.UpdateGame(spain.Id, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), game.HomeScore)
+	assert.Equal(t, uint(1), game.AwayScore)
+	_, err = board.UpdateGame(99, 2, 1)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	game, err = board.RemoveGame(usa.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.USA, game.HomeTeam)
+	_, err = board.RemoveGame(usa.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	games := board.GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, true, GameEquality(&models.Game{Id: spain.Id, HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 2, AwayScore: 1}, games[0]))
+
+	next, err := board.StartGame("Japan", "China")
+	assert.NoError(t, err)
+	assert.Equal(t, usa.Id+1, next.Id)
+
+	_, err = board.StartGame("Egypt", "Egypt")
+	assert.ErrorIs(t, err, models.ErrSameTeam)
+	_, err = board.ScheduleGame("China", "USA")
+	assert.ErrorIs(t, err, models.ErrTeamAlreadyPlaying)
+	_, err = board.StartGame("Italy", "USA")
+	assert.NoError(t, err)
+}
+
+func TestSQLScoreBase_Insert(t *testing.T) {
+	store, path := newTestSQLStore(t)
+	base := store.ScoreBase()
+	memory := NewScoreBase()
+
+	for _, game := range fileStoreTestData {
+		assert.NoError(t, base.Insert(game))
+		assert.NoError(t, memory.Insert(game))
+		assertSameGames(t, memory.GetGames(), base.GetGames())
+	}
+	assert.Error(t, base.Insert(fileStoreTestData[0]))
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assertSameGames(t, memory.GetGames(), reopened.ScoreBase().GetGames())
+}
+
+func TestSQLStore_GetGame(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	board := store.Board()
+	base := store.ScoreBase()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+	assert.NoError(t, err)
+
+	live, err := board.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), live.HomeScore)
+	assert.Equal(t, 1, len(live.Goals))
+	_, err = base.GetGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	_, err = store.FinishGame(game.Id)
+	assert.NoError(t, err)
+	_, err = board.GetGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	finished, err := base.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusFinished, finished.Status)
+	assert.Equal(t, "Morata", finished.Goals[0].Player)
+}
+
+func TestSQLBoard_RestoreGame(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	board := store.Board()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 10, Team: models.Spain, Player: "Morata", Type: models.GoalRegular})
+	assert.NoError(t, err)
+	removed, err := board.RemoveGame(game.Id)
+	assert.NoError(t, err)
+
+	other, err := board.StartGame("Brazil", "Italy")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, board.RestoreGame(removed), models.ErrTeamAlreadyPlaying)
+	_, err = board.RemoveGame(other.Id)
+	assert.NoError(t, err)
+
+	assert.NoError(t, board.RestoreGame(removed))
+	restored, err := board.GetGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, removed, restored)
+	assert.Equal(t, "Morata", restored.Goals[0].Player)
+}
+
+func TestSQLStore_FinishGame(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	board := store.Board()
+	base := store.ScoreBase()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 0)
+	assert.NoError(t, err)
+
+	finished, err := store.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), finished.HomeScore)
+	assert.Equal(t, 0, len(board.GetGames()))
+	assert.Equal(t, 1, len(base.GetGames()))
+
+	_, err = store.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	// A game whose id is already in the score base cannot be inserted, so it has to stay on the board.
+	clash, err := board.StartGame("USA", "Italy")
+	assert.NoError(t, err)
+	assert.NoError(t, base.Insert(&models.Game{Id: clash.Id, HomeTeam: models.USA, AwayTeam: models.Italy}))
+	_, err = store.FinishGame(clash.Id)
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(board.GetGames()))
+	assert.Equal(t, 2, len(base.GetGames()))
+}
+
+func TestSQLScoreBase_Query(t *testing.T) {
+	store, _ := newTestSQLStore(t)
+	defer store.Close()
+	base := store.ScoreBase()
+	for _, game := range queryTestData() {
+		assert.NoError(t, base.Insert(game))
+	}
+
+	for _, tt := range queryTests() {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := base.Query(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIds, gameIds(page.Games))
+			assert.Equal(t, tt.wantNext, page.Next)
+		})
+	}
+
+	_, err := base.Query(SummaryQuery{After: 99})
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
+
+func TestSQLBoard_RecordGoal(t *testing.T) {
+	store, path := newTestSQLStore(t)
+	board := store.Board()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	memory := NewScoreBoard()
+	_, err = memory.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+
+	goals := []models.Goal{
+		{Minute: 40, Team: models.Spain, Player: "Morata", Type: models.GoalRegular},
+		{Minute: 20, Team: models.Brazil, Player: "Ramos", Type: models.GoalOwn},
+		{Minute: 40, Team: models.Brazil, Player: "Neymar", Type: models.GoalPenalty},
+	}
+	for _, goal := range goals {
+		want, err := memory.RecordGoal(game.Id, goal)
+		assert.NoError(t, err)
+		got, err := board.RecordGoal(game.Id, goal)
+		assert.NoError(t, err)
+		assert.Equal(t, want.Goals, got.Goals)
+		assert.Equal(t, want.HomeScore, got.HomeScore)
+		assert.Equal(t, want.AwayScore, got.AwayScore)
+	}
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 50, Team: models.Italy, Player: "Rossi", Type: models.GoalRegular})
+	assert.ErrorIs(t, err, models.ErrInvalidGoal)
+	_, err = board.RecordGoal(99, goals[0])
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+
+	want := memory.GetGames()[0].Goals
+	assert.Equal(t, want, board.GetGames()[0].Goals)
+
+	finished, err := store.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, want, finished.Goals)
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	games := reopened.ScoreBase().GetGames()
+	assert.Equal(t, 1, len(games))
+	assert.Equal(t, want, games[0].Goals)
+}
+
+func TestSQLBoard_TransitionGame(t *testing.T) {
+	store, path := newTestSQLStore(t)
+	board := store.Board()
+
+	game, err := board.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusScheduled, game.Status)
+	_, err = board.UpdateGame(game.Id, 1, 0)
+	assert.ErrorIs(t, err, models.ErrScoreUpdateNotAllowed)
+	_, err = store.FinishGame(game.Id)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+
+	game, err = board.TransitionGame(game.Id, models.TransitionKickoff)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusLive, game.Status)
+	_, err = board.TransitionGame(game.Id, models.TransitionResume)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	_, err = board.TransitionGame(99, models.TransitionResume)
+	assert.ErrorIs(t, err, models.ErrGameNotFound)
+	game, err = board.TransitionGame(game.Id, models.TransitionExtraTime)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusExtraTime, board.GetGames()[0].Status)
+
+	finished, err := store.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusFinished, finished.Status)
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, models.StatusFinished, reopened.ScoreBase().GetGames()[0].Status)
+}
+
+func TestSQLBoard_KickoffGame(t *testing.T) {
+	store, path := newTestSQLStore(t)
+	board := store.Board()
+
+	kickoff := time.Date(2026, 6, 11, 18, 0, 0, 0, time.UTC)
+	game, err := board.ScheduleGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	game, err = board.KickoffGame(game.Id, kickoff)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusLive, game.Status)
+	_, err = board.KickoffGame(game.Id, kickoff)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, true, kickoff.Equal(reopened.Board().GetGames()[0].StartedAt))
+}
+
+func TestSQLBoard_Knockout(t *testing.T) {
+	store, path := newTestSQLStore(t)
+	board := store.Board()
+
+	game, err := board.StartGame("Spain", "Brazil")
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(game.Id, models.TransitionExtraTime)
+	assert.NoError(t, err)
+	_, err = board.RecordGoal(game.Id, models.Goal{Minute: 100, Team: models.Brazil, Player: "Neymar", Type: models.GoalRegular})
+	assert.NoError(t, err)
+	_, err = board.UpdateGame(game.Id, 1, 1)
+	assert.NoError(t, err)
+	_, err = board.TransitionGame(game.Id, models.TransitionShootout)
+	assert.NoError(t, err)
+	live, err := board.UpdateShootout(game.Id, 2, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, live, board.GetGames()[0])
+
+	finished, err := store.FinishGame(game.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Brazil, finished.Winner())
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewSQLStore(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+	games := reopened.ScoreBase().GetGames()
+	assert.Equal(t, &models.Score{Home: 0, Away: 0}, games[0].RegulationScore)
+	assert.Equal(t, &models.Score{Home: 1, Away: 1}, games[0].ExtraTimeScore)
+	assert.Equal(t, &models.Score{Home: 2, Away: 4}, games[0].PenaltyScore)
+	assert.Equal(t, models.Brazil, games[0].Winner())
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseKeepAliveInterval is the interval of comment lines sent to idle event stream clients,
+// so proxies do not close the connection.
+const sseKeepAliveInterval = 15 * time.Second
+
+// handleEvents streams events of the EventBroker to the client using Server-Sent Events.
+// Clients resume the stream by sending the id of the last received event in the Last-Event-ID header
+// or in the lastEventId query parameter, the missed events are replayed from the broker history.
+func (a *App) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		a.logger.Println("response writer does not support flushing")
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+	var lastId uint64
+	if lastEventId != "" {
+		var err error
+		lastId, err = strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			a.logger.Println("failed to get last event id from request: ", err)
+			http.Error(w, "Invalid last event id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub, missed := a.events.Subscribe(lastId)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// The client was too slow and has been dropped by the broker, it will reconnect with Last-Event-ID.
+				return
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSEEvent writes a single event in the Server-Sent Events format.
+func writeSSEEvent(w http.ResponseWriter, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
@@ -0,0 +1,209 @@
+// Package stats keeps per-team statistics and head-to-head records of finished games. The statistics are indexed
+// by team and by pair of teams as games are recorded, so a query only reads the games of the teams it is about.
+package stats
+
+import (
+	"cmp"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+	"sync"
+)
+
+// TeamStats represents the aggregated record of a team over its finished games. A game decided by a penalty
+// shootout counts as a draw, the same as in official statistics. BiggestWin is the win with the largest margin,
+// the one with more goals scored among wins with the same margin and the earlier one among those.
+type TeamStats struct {
+	Team             models.Countries `json:"team"`
+	Matches          uint             `json:"matches"`
+	Won              uint             `json:"won"`
+	Drawn            uint             `json:"drawn"`
+	Lost             uint             `json:"lost"`
+	GoalsFor         uint             `json:"goals_for"`
+	GoalsAgainst     uint             `json:"goals_against"`
+	CurrentWinStreak uint             `json:"current_win_streak"`
+	LongestWinStreak uint             `json:"longest_win_streak"`
+	BiggestWin       *models.Game     `json:"biggest_win,omitempty"`
+}
+
+// HeadToHead represents the record of a team against an opponent, with their games in the order they were played.
+type HeadToHead struct {
+	Team          models.Countries `json:"team"`
+	Opponent      models.Countries `json:"opponent"`
+	Matches       uint             `json:"matches"`
+	TeamWins      uint             `json:"team_wins"`
+	OpponentWins  uint             `json:"opponent_wins"`
+	Draws         uint             `json:"draws"`
+	TeamGoals     uint             `json:"team_goals"`
+	OpponentGoals uint             `json:"opponent_goals"`
+	Games         []*models.Game   `json:"games"`
+}
+
+// pair is the key of the games between two teams, the teams are ordered so both directions share the key.
+type pair struct {
+	first, second models.Countries
+}
+
+// Index keeps the finished games of every team and every pair of teams in the order they were played.
+// Totals are updated as games are recorded, streaks are computed from the games of the team on demand.
+// Index is safe for concurrent use.
+type Index struct {
+	teams    map[models.Countries]*TeamStats
+	games    map[models.Countries][]*models.Game
+	pairs    map[pair][]*models.Game
+	recorded map[uint32]bool
+	lock     sync.RWMutex
+}
+
+// NewIndex returns a new empty index.
+func NewIndex() *Index {
+	return &Index{
+		teams:    make(map[models.Countries]*TeamStats),
+		games:    make(map[models.Countries][]*models.Game),
+		pairs:    make(map[pair][]*models.Game),
+		recorded: make(map[uint32]bool),
+	}
+}
+
+// Record adds the game to the statistics of its teams and to their head-to-head record,
+// and reports whether the index was changed. Abandoned games and games recorded before, by id, are ignored.
+// Games may be recorded in any order, they are kept in the order they were played.
+func (x *Index) Record(game *models.Game) bool {
+	if game.Status == models.StatusAbandoned {
+		return false
+	}
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if x.recorded[game.Id] {
+		return false
+	}
+	x.recorded[game.Id] = true
+
+	for _, team := range []models.Countries{game.HomeTeam, game.AwayTeam} {
+		stats, ok := x.teams[team]
+		if !ok {
+			stats = &TeamStats{Team: team}
+			x.teams[team] = stats
+		}
+		stats.record(game)
+		x.games[team] = insertPlayed(x.games[team], game)
+	}
+	key := pairOf(game.HomeTeam, game.AwayTeam)
+	x.pairs[key] = insertPlayed(x.pairs[key], game)
+	return true
+}
+
+// Team returns the statistics of the team, with zero totals for a team without finished games.
+func (x *Index) Team(team models.Countries) TeamStats {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	stats, ok := x.teams[team]
+	if !ok {
+		return TeamStats{Team: team}
+	}
+	result := *stats
+	result.CurrentWinStreak, result.LongestWinStreak = winStreaks(team, x.games[team])
+	return result
+}
+
+// HeadToHead returns the record of the team against the opponent, from the point of view of the team.
+func (x *Index) HeadToHead(team, opponent models.Countries) HeadToHead {
+	x.lock.RLock()
+	games := slices.Clone(x.pairs[pairOf(team, opponent)])
+	x.lock.RUnlock()
+
+	result := HeadToHead{Team: team, Opponent: opponent, Games: games}
+	if result.Games == nil {
+		result.Games = []*models.Game{}
+	}
+	for _, game := range games {
+		scored, conceded := goalsOf(team, game)
+		result.Matches++
+		result.TeamGoals += scored
+		result.OpponentGoals += conceded
+		switch {
+		case scored > conceded:
+			result.TeamWins++
+		case scored < conceded:
+			result.OpponentWins++
+		default:
+			result.Draws++
+		}
+	}
+	return result
+}
+
+// record adds the game to the totals of the team.
+func (x *TeamStats) record(game *models.Game) {
+	scored, conceded := goalsOf(x.Team, game)
+	x.Matches++
+	x.GoalsFor += scored
+	x.GoalsAgainst += conceded
+	switch {
+	case scored > conceded:
+		x.Won++
+		if x.BiggestWin == nil || isBiggerWin(x.Team, game, x.BiggestWin) {
+			x.BiggestWin = game
+		}
+	case scored == conceded:
+		x.Drawn++
+	default:
+		x.Lost++
+	}
+}
+
+// isBiggerWin reports whether the win of the team in the game is bigger than its win in the other game.
+func isBiggerWin(team models.Countries, game, other *models.Game) bool {
+	scored, conceded := goalsOf(team, game)
+	otherScored, otherConceded := goalsOf(team, other)
+	if margin, otherMargin := scored-conceded, otherScored-otherConceded; margin != otherMargin {
+		return margin > otherMargin
+	}
+	if scored != otherScored {
+		return scored > otherScored
+	}
+	return byPlayed(game, other) < 0
+}
+
+// winStreaks returns the number of wins in a row of the team up to its last game and the longest run of wins
+// in its games, which are in the order they were played.
+func winStreaks(team models.Countries, games []*models.Game) (current, longest uint) {
+	for _, game := range games {
+		if scored, conceded := goalsOf(team, game); scored > conceded {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return current, longest
+}
+
+// goalsOf returns the goals scored and conceded by the team in the game.
+func goalsOf(team models.Countries, game *models.Game) (scored, conceded uint) {
+	if game.HomeTeam == team {
+		return game.HomeScore, game.AwayScore
+	}
+	return game.AwayScore, game.HomeScore
+}
+
+// pairOf returns the key of the games between the teams.
+func pairOf(a, b models.Countries) pair {
+	return pair{first: min(a, b), second: max(a, b)}
+}
+
+// insertPlayed inserts the game into the games kept in the order they were played.
+func insertPlayed(games []*models.Game, game *models.Game) []*models.Game {
+	i, _ := slices.BinarySearchFunc(games, game, byPlayed)
+	return slices.Insert(games, i, game)
+}
+
+// byPlayed orders games by start time, then by id, the earliest game first.
+func byPlayed(a, b *models.Game) int {
+	if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Id, b.Id)
+}
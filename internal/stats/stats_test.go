@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2026, 6, 1, 18, 0, 0, 0, time.UTC)
+
+func TestIndex_Team(t *testing.T) {
+	shootout := &models.Game{Id: 5, HomeTeam: models.Spain, HomeScore: 1, AwayTeam: models.Germany, AwayScore: 1, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 5), PenaltyScore: &models.Score{Home: 4, Away: 2}}
+	abandoned := &models.Game{Id: 8, HomeTeam: models.Spain, HomeScore: 0, AwayTeam: models.Japan, AwayScore: 5, Status: models.StatusAbandoned, StartedAt: start.AddDate(0, 0, 8)}
+	games := []*models.Game{
+		&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 1)},
+		&models.Game{Id: 2, HomeTeam: models.France, HomeScore: 0, AwayTeam: models.Spain, AwayScore: 3, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 2)},
+		&models.Game{Id: 3, HomeTeam: models.Spain, HomeScore: 4, AwayTeam: models.Japan, AwayScore: 1, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 3)},
+		&models.Game{Id: 4, HomeTeam: models.Brazil, HomeScore: 2, AwayTeam: models.Spain, AwayScore: 1, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 4)},
+		shootout,
+		&models.Game{Id: 6, HomeTeam: models.Spain, HomeScore: 1, AwayTeam: models.France, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 6)},
+		&models.Game{Id: 7, HomeTeam: models.Germany, HomeScore: 0, AwayTeam: models.Spain, AwayScore: 5, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 7)},
+		abandoned,
+	}
+
+	tests := []struct {
+		name  string
+		order []int
+	}{
+		{name: "In the order played", order: []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{name: "Out of order", order: []int{6, 2, 7, 0, 5, 3, 1, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewIndex()
+			for _, i := range tt.order {
+				index.Record(games[i])
+			}
+			stats := index.Team(models.Spain)
+			assert.Equal(t, models.Spain, stats.Team)
+			assert.Equal(t, uint(7), stats.Matches)
+			assert.Equal(t, uint(5), stats.Won)
+			// The shootout counts as a draw.
+			assert.Equal(t, uint(1), stats.Drawn)
+			assert.Equal(t, uint(1), stats.Lost)
+			assert.Equal(t, uint(17), stats.GoalsFor)
+			assert.Equal(t, uint(4), stats.GoalsAgainst)
+			assert.Equal(t, uint(2), stats.CurrentWinStreak)
+			assert.Equal(t, uint(3), stats.LongestWinStreak)
+			assert.Equal(t, uint32(7), stats.BiggestWin.Id)
+		})
+	}
+}
+
+func TestIndex_Team_BiggestWin(t *testing.T) {
+	index := NewIndex()
+	index.Record(&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 1)})
+	index.Record(&models.Game{Id: 2, HomeTeam: models.Spain, HomeScore: 3, AwayTeam: models.Japan, AwayScore: 1, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 2)})
+	index.Record(&models.Game{Id: 3, HomeTeam: models.France, HomeScore: 1, AwayTeam: models.Spain, AwayScore: 3, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 0)})
+	// Of the wins by two goals the one with more goals scored and, among those, the earlier one is the biggest.
+	assert.Equal(t, uint32(3), index.Team(models.Spain).BiggestWin.Id)
+
+	assert.Equal(t, TeamStats{Team: models.Japan, Matches: 1, Lost: 1, GoalsFor: 1, GoalsAgainst: 3}, index.Team(models.Japan))
+	assert.Equal(t, TeamStats{Team: models.Germany}, index.Team(models.Germany))
+}
+
+func TestIndex_Record(t *testing.T) {
+	index := NewIndex()
+	game := &models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 1)}
+	assert.Equal(t, true, index.Record(game))
+	// Recording a game twice, e.g. when an interrupted finish is retried, must not count it twice.
+	assert.Equal(t, false, index.Record(game))
+
+	abandoned := &models.Game{Id: 2, HomeTeam: models.Spain, HomeScore: 0, AwayTeam: models.Brazil, AwayScore: 3, Status: models.StatusAbandoned, StartedAt: start.AddDate(0, 0, 2)}
+	assert.Equal(t, false, index.Record(abandoned))
+	assert.Equal(t, uint(1), index.Team(models.Spain).Matches)
+}
+
+func TestIndex_HeadToHead(t *testing.T) {
+	index := NewIndex()
+	index.Record(&models.Game{Id: 3, HomeTeam: models.Brazil, HomeScore: 1, AwayTeam: models.Spain, AwayScore: 1, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 3)})
+	index.Record(&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 1)})
+	index.Record(&models.Game{Id: 2, HomeTeam: models.Brazil, HomeScore: 3, AwayTeam: models.Spain, AwayScore: 2, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 2)})
+	index.Record(&models.Game{Id: 4, HomeTeam: models.Spain, HomeScore: 5, AwayTeam: models.Japan, AwayScore: 0, Status: models.StatusFinished, StartedAt: start.AddDate(0, 0, 4)})
+
+	record := index.HeadToHead(models.Spain, models.Brazil)
+	assert.Equal(t, models.Spain, record.Team)
+	assert.Equal(t, models.Brazil, record.Opponent)
+	assert.Equal(t, uint(3), record.Matches)
+	assert.Equal(t, uint(1), record.TeamWins)
+	assert.Equal(t, uint(1), record.OpponentWins)
+	assert.Equal(t, uint(1), record.Draws)
+	assert.Equal(t, uint(5), record.TeamGoals)
+	assert.Equal(t, uint(4), record.OpponentGoals)
+	ids := make([]uint32, 0, len(record.Games))
+	for _, game := range record.Games {
+		ids = append(ids, game.Id)
+	}
+	assert.Equal(t, []uint32{1, 2, 3}, ids)
+
+	reverse := index.HeadToHead(models.Brazil, models.Spain)
+	assert.Equal(t, record.TeamWins, reverse.OpponentWins)
+	assert.Equal(t, record.TeamGoals, reverse.OpponentGoals)
+	assert.Equal(t, record.OpponentGoals, reverse.TeamGoals)
+
+	none := index.HeadToHead(models.Brazil, models.Japan)
+	assert.Equal(t, uint(0), none.Matches)
+	assert.NotNil(t, none.Games)
+}
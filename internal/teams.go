@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TeamStoring defines methods for managing the teams games can be played by. Teams returns all teams,
+// including the retired ones, AddTeam adds a new team or brings back a retired one, and RetireTeam retires a team
+// so it cannot start new games. It is implemented by models.TeamRegistry and by FileTeams.
+type TeamStoring interface {
+	Teams() []models.Team
+	AddTeam(team models.Team) (models.Team, error)
+	RetireTeam(id models.Countries) (models.Team, error)
+}
+
+// teamsConfig represents the content of a team config file.
+type teamsConfig struct {
+	Teams []models.Team `json:"teams" yaml:"teams"`
+}
+
+// FileTeams represents a team registry loaded from a config file that is written back on every change,
+// so teams added or retired at run time are kept across restarts. The file is JSON if its name ends with .json
+// and YAML otherwise.
+type FileTeams struct {
+	registry *models.TeamRegistry
+	path     string
+	lock     sync.Mutex
+}
+
+// NewFileTeams loads the team registry from the config file at the provided path.
+func NewFileTeams(path string) (*FileTeams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read team config: %w", err)
+	}
+	var config teamsConfig
+	if isJSONConfig(path) {
+		err = json.Unmarshal(data, &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode team config %s: %w", path, err)
+	}
+
+	registry, err := models.NewTeamRegistry(config.Teams...)
+	if err != nil {
+		return nil, fmt.Errorf("load team config %s: %w", path, err)
+	}
+	return &FileTeams{registry: registry, path: path}, nil
+}
+
+// Registry returns the registry of the loaded teams, which is kept up to date with the changes made by FileTeams.
+func (x *FileTeams) Registry() *models.TeamRegistry {
+	return x.registry
+}
+
+// Teams returns all teams, including the retired ones, ordered by id.
+func (x *FileTeams) Teams() []models.Team {
+	return x.registry.Teams()
+}
+
+// AddTeam adds the team the same way as models.TeamRegistry.AddTeam and writes the config file.
+// The registry is changed only once the file is written.
+func (x *FileTeams) AddTeam(team models.Team) (models.Team, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	next, err := x.copyRegistry()
+	if err != nil {
+		return models.Team{}, err
+	}
+	if _, err := next.AddTeam(team); err != nil {
+		return models.Team{}, err
+	}
+	if err := x.save(next.Teams()); err != nil {
+		return models.Team{}, err
+	}
+	return x.registry.AddTeam(team)
+}
+
+// RetireTeam retires the team the same way as models.TeamRegistry.RetireTeam and writes the config file.
+// The registry is changed only once the file is written.
+func (x *FileTeams) RetireTeam(id models.Countries) (models.Team, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	next, err := x.copyRegistry()
+	if err != nil {
+		return models.Team{}, err
+	}
+	if _, err := next.RetireTeam(id); err != nil {
+		return models.Team{}, err
+	}
+	if err := x.save(next.Teams()); err != nil {
+		return models.Team{}, err
+	}
+	return x.registry.RetireTeam(id)
+}
+
+// copyRegistry returns a new registry of the current teams, to try a change on before it is written.
+func (x *FileTeams) copyRegistry() (*models.TeamRegistry, error) {
+	registry, err := models.NewTeamRegistry(x.registry.Teams()...)
+	if err != nil {
+		return nil, fmt.Errorf("copy team registry: %w", err)
+	}
+	return registry, nil
+}
+
+// save writes the teams to a temporary file and renames it over the config file,
+// so a crash leaves either the old or the new config.
+func (x *FileTeams) save(teams []models.Team) error {
+	var data []byte
+	var err error
+	if isJSONConfig(x.path) {
+		data, err = json.MarshalIndent(teamsConfig{Teams: teams}, "", "  ")
+	} else {
+		data, err = yaml.Marshal(teamsConfig{Teams: teams})
+	}
+	if err != nil {
+		return fmt.Errorf("encode team config: %w", err)
+	}
+
+	if err := writeFileSync(x.path+journalTempSuffix, data); err != nil {
+		return err
+	}
+	if err := os.Rename(x.path+journalTempSuffix, x.path); err != nil {
+		return fmt.Errorf("replace team config: %w", err)
+	}
+	return syncDir(filepath.Dir(x.path))
+}
+
+// isJSONConfig reports whether the config file at the provided path is JSON rather than YAML.
+func isJSONConfig(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
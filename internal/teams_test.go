@@ -0,0 +1,330 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/Marian2701/CodingExercise/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTeams makes the registry of the provided teams the registry in use until the end of the test.
+func useTeams(t *testing.T, teams ...models.Team) *models.TeamRegistry {
+	registry, err := models.NewTeamRegistry(teams...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := models.Teams()
+	models.SetTeams(registry)
+	t.Cleanup(func() {
+		models.SetTeams(previous)
+	})
+	return registry
+}
+
+// resolve returns the team of the registry matching the name, NotACountry if there is none.
+func resolve(registry *models.TeamRegistry, name string) models.Countries {
+	team, _ := registry.Resolve(name)
+	return team
+}
+
+func TestNewTeamRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		teams   []models.Team
+		wantErr error
+	}{
+		{name: "Built-in teams", teams: models.BuiltinTeams()},
+		{name: "Missing id", teams: []models.Team{{Id: " ", Name: "Nobody"}}, wantErr: models.ErrInvalidTeam},
+		{name: "Duplicate id", teams: []models.Team{{Id: "Ajax"}, {Id: "Ajax"}}, wantErr: models.ErrDuplicateTeam},
+		{name: "Duplicate short code", teams: []models.Team{{Id: "Ajax", ShortCode: "AJA"}, {Id: "Ajaccio", ShortCode: "aja"}}, wantErr: models.ErrDuplicateTeam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := models.NewTeamRegistry(tt.teams...)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTeamRegistry(t *testing.T) {
+	registry, err := models.NewTeamRegistry(models.Team{Id: "Ajax", ShortCode: "AJA"})
+	assert.NoError(t, err)
+
+	team, err := registry.AddTeam(models.Team{Id: " Celtic ", ShortCode: "cel", IsoCode: "gb", Aliases: []string{"The Bhoys", " "}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Team{Id: "Celtic", Name: "Celtic", ShortCode: "CEL", IsoCode: "GB", Aliases: []string{"The Bhoys"}}, team)
+	assert.Equal(t, models.Countries("Celtic"), resolve(registry, "Celtic"))
+
+	_, err = registry.AddTeam(models.Team{Id: "Celtic"})
+	assert.ErrorIs(t, err, models.ErrDuplicateTeam)
+	_, err = registry.AddTeam(models.Team{Id: "Ajaccio", ShortCode: "AJA"})
+	assert.ErrorIs(t, err, models.ErrDuplicateTeam)
+
+	team, err = registry.RetireTeam("Ajax")
+	assert.NoError(t, err)
+	assert.Equal(t, true, team.Retired)
+	assert.Equal(t, models.Countries(models.NotACountry), resolve(registry, "Ajax"))
+	retired, err := registry.ResolveAny("aja")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Countries("Ajax"), retired)
+	assert.Equal(t, 2, len(registry.Teams()))
+	assert.Equal(t, []models.Team{{Id: "Celtic", Name: "Celtic", ShortCode: "CEL", IsoCode: "GB", Aliases: []string{"The Bhoys"}}}, registry.Active())
+	_, err = registry.RetireTeam("Ajax")
+	assert.ErrorIs(t, err, models.ErrTeamNotFound)
+
+	// A retired team is brought back by adding it again.
+	_, err = registry.AddTeam(models.Team{Id: "Ajax", Name: "AFC Ajax", ShortCode: "AJA"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.Countries("Ajax"), resolve(registry, "Ajax"))
+}
+
+func TestResolveTeam(t *testing.T) {
+	tests := []struct {
+		name            string
+		value           string
+		want            models.Countries
+		wantSuggestions []models.Countries
+	}{
+		{name: "Id", value: "Spain", want: models.Spain},
+		{name: "Lower case", value: "usa", want: models.USA},
+		{name: "Display name", value: "United Kingdom", want: models.UK},
+		{name: "Alias", value: "great britain", want: models.UK},
+		{name: "FIFA code", value: "RSA", want: models.SouthAfrica},
+		{name: "ISO alpha-2 code", value: "de", want: models.Germany},
+		{name: "ISO alpha-3 code", value: "DNK", want: models.Denmark},
+		{name: "Hyphen", value: "South-Africa", want: models.SouthAfrica},
+		{name: "Extra whitespace", value: "  south   africa ", want: models.SouthAfrica},
+		{name: "Dots", value: "U.S.A.", want: models.USA},
+		{name: "Diacritics", value: "ESPAÑA", want: models.Spain},
+		{name: "Diacritics removed", value: "espana", want: models.Spain},
+		{name: "Typo", value: "Spian", wantSuggestions: []models.Countries{models.Spain}},
+		{name: "Dropped letter in the middle", value: "Argentna", wantSuggestions: []models.Countries{models.Argentina}},
+		{name: "Dropped letter", value: "Indonesa", wantSuggestions: []models.Countries{models.Indonesia}},
+		{name: "Unknown", value: "Narnia", wantSuggestions: nil},
+		{name: "Empty", value: " ", wantSuggestions: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, err := models.ResolveTeam(tt.value)
+			if tt.want != models.NotACountry {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, team)
+				assert.Equal(t, tt.want, models.GetCountryFromString(tt.value))
+				return
+			}
+
+			assert.ErrorIs(t, err, models.ErrInvalidCountry)
+			assert.Equal(t, models.Countries(models.NotACountry), team)
+			var unknown *models.UnknownTeamError
+			if assert.ErrorAs(t, err, &unknown) {
+				assert.Equal(t, tt.value, unknown.Name)
+				assert.Equal(t, tt.wantSuggestions, unknown.Suggestions)
+			}
+		})
+	}
+
+	_, err := models.ResolveTeam("Spian")
+	assert.EqualError(t, err, `invalid country: "Spian", did you mean Spain?`)
+}
+
+func TestTeamRegistry_Resolve_Ambiguous(t *testing.T) {
+	registry, err := models.NewTeamRegistry(
+		models.Team{Id: "Inter", Name: "Internazionale", Aliases: []string{"Inter"}},
+		models.Team{Id: "Inter Miami", Aliases: []string{"Miami", "Inter"}},
+		models.Team{Id: "Miami FC", Aliases: []string{"Miami"}},
+	)
+	assert.NoError(t, err)
+	// The id of a team takes precedence over the alias of another team, an alias of two teams resolves to neither.
+	assert.Equal(t, models.Countries("Inter"), resolve(registry, "inter"))
+	assert.Equal(t, models.Countries(models.NotACountry), resolve(registry, "Miami"))
+
+	_, err = models.NewTeamRegistry(models.Team{Id: "Ajax"}, models.Team{Id: "AJAX"})
+	assert.ErrorIs(t, err, models.ErrDuplicateTeam)
+	_, err = models.NewTeamRegistry(models.Team{Id: "..."})
+	assert.ErrorIs(t, err, models.ErrInvalidTeam)
+}
+
+func TestApi_StartMatch_ResolveTeam(t *testing.T) {
+	app := NewApp(NewScoreBase(), NewScoreBoard())
+	app.InitRoutes()
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "south-africa", "away_team": "BRA"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var game models.Game
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&game))
+	assert.Equal(t, models.SouthAfrica, game.HomeTeam)
+	assert.Equal(t, models.Brazil, game.AwayTeam)
+
+	rec = doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Spian", "away_team": "Japan"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var body errorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, []models.Countries{models.Spain}, body.Suggestions)
+}
+
+func TestScoreBoard_StartGame_TeamRegistry(t *testing.T) {
+	registry := useTeams(t, models.Team{Id: "Ajax"}, models.Team{Id: "Celtic"})
+
+	board := NewScoreBoard()
+	game, err := board.StartGame("Ajax", "Celtic")
+	assert.NoError(t, err)
+	assert.Equal(t, models.Countries("Ajax"), game.HomeTeam)
+	_, err = board.StartGame(string(models.Spain), "Celtic")
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+
+	_, err = registry.RetireTeam("Celtic")
+	assert.NoError(t, err)
+	_, err = board.RemoveGame(game.Id)
+	assert.NoError(t, err)
+	_, err = board.StartGame("Ajax", "Celtic")
+	assert.ErrorIs(t, err, models.ErrInvalidCountry)
+}
+
+func TestFileTeams(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "YAML",
+			file: "teams.yaml",
+			content: `teams:
+  - id: Ajax
+    name: AFC Ajax
+    short_code: AJA
+    iso_code: NL
+  - id: Celtic
+    aliases: [The Bhoys]
+`,
+		},
+		{
+			name:    "JSON",
+			file:    "teams.json",
+			content: `{"teams": [{"id": "Ajax", "name": "AFC Ajax", "short_code": "AJA", "iso_code": "NL"}, {"id": "Celtic", "aliases": ["The Bhoys"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			teams, err := NewFileTeams(path)
+			assert.NoError(t, err)
+			assert.Equal(t, []models.Team{
+				{Id: "Ajax", Name: "AFC Ajax", ShortCode: "AJA", IsoCode: "NL"},
+				{Id: "Celtic", Name: "Celtic", Aliases: []string{"The Bhoys"}},
+			}, teams.Teams())
+
+			_, err = teams.AddTeam(models.Team{Id: "Rangers", ShortCode: "RAN"})
+			assert.NoError(t, err)
+			_, err = teams.RetireTeam("Celtic")
+			assert.NoError(t, err)
+			_, err = teams.RetireTeam("Hearts")
+			assert.ErrorIs(t, err, models.ErrTeamNotFound)
+
+			reopened, err := NewFileTeams(path)
+			assert.NoError(t, err)
+			assert.Equal(t, teams.Teams(), reopened.Teams())
+			assert.Equal(t, models.Countries("Rangers"), resolve(reopened.Registry(), "Rangers"))
+			assert.Equal(t, models.Countries(models.NotACountry), resolve(reopened.Registry(), "Celtic"))
+		})
+	}
+
+	_, err := NewFileTeams(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestApi_Teams(t *testing.T) {
+	registry := useTeams(t, models.Team{Id: "Ajax", ShortCode: "AJA"}, models.Team{Id: "Celtic"})
+	app := NewApp(NewScoreBase(), NewScoreBoard(), WithTeams(registry))
+	app.InitRoutes()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "Add team", method: http.MethodPost, path: "/api/v1/teams", body: `{"id": "Rangers", "name": "Rangers FC"}`, want: http.StatusCreated},
+		{name: "Add team without id", method: http.MethodPost, path: "/api/v1/teams", body: `{"name": "Nobody"}`, want: http.StatusBadRequest},
+		{name: "Add existing team", method: http.MethodPost, path: "/api/v1/teams", body: `{"id": "Ajax"}`, want: http.StatusConflict},
+		{name: "Add team with a taken short code", method: http.MethodPost, path: "/api/v1/teams", body: `{"id": "Ajaccio", "short_code": "AJA"}`, want: http.StatusConflict},
+		{name: "Invalid body", method: http.MethodPost, path: "/api/v1/teams", body: `{`, want: http.StatusBadRequest},
+		{name: "Retire team", method: http.MethodPost, path: "/api/v1/teams/Celtic/retire", want: http.StatusOK},
+		{name: "Retire retired team", method: http.MethodPost, path: "/api/v1/teams/Celtic/retire", want: http.StatusNotFound},
+		{name: "Start game of added team", method: http.MethodPost, path: "/api/v1/matches", body: `{"home_team": "Rangers", "away_team": "Ajax"}`, want: http.StatusCreated},
+		{name: "Start game of retired team", method: http.MethodPost, path: "/api/v1/matches", body: `{"home_team": "Celtic", "away_team": "Ajax"}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+
+	rec := doRequest(app, http.MethodGet, "/api/v1/teams", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var teams []models.Team
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&teams))
+	assert.Equal(t, []models.Team{
+		{Id: "Ajax", Name: "Ajax", ShortCode: "AJA"},
+		{Id: "Celtic", Name: "Celtic", Retired: true},
+		{Id: "Rangers", Name: "Rangers FC"},
+	}, teams)
+
+	rec = doRequest(app, http.MethodGet, "/", "")
+	assert.Contains(t, rec.Body.String(), `<option value="Rangers">Rangers FC</option>`)
+	assert.NotContains(t, rec.Body.String(), `<option value="Celtic">`)
+}
+
+func TestApi_RetiredTeam(t *testing.T) {
+	registry := useTeams(t, models.Team{Id: "Ajax"}, models.Team{Id: "Celtic", Aliases: []string{"The Bhoys"}})
+	index := stats.NewIndex()
+	app := NewApp(NewRecordingScoreBase(NewScoreBase(), index), NewScoreBoard(), WithTeams(registry), WithStats(index))
+	app.InitRoutes()
+
+	rec := doRequest(app, http.MethodPost, "/api/v1/matches", `{"home_team": "Ajax", "away_team": "Celtic"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	_, err := registry.RetireTeam("Celtic")
+	assert.NoError(t, err)
+
+	// A team retired while playing still scores in its game and keeps its statistics.
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "Goal of retired team", method: http.MethodPost, path: "/api/v1/matches/1/goals", body: `{"minute": 10, "team": "Celtic", "player": "Larsson"}`, want: http.StatusCreated},
+		{name: "Goal of retired team by alias", method: http.MethodPost, path: "/api/v1/matches/1/goals", body: `{"minute": 20, "team": "the bhoys", "player": "Larsson"}`, want: http.StatusCreated},
+		{name: "Goal of team not playing", method: http.MethodPost, path: "/api/v1/matches/1/goals", body: `{"minute": 30, "team": "Spain", "player": "Morata"}`, want: http.StatusBadRequest},
+		{name: "Goal of missing game", method: http.MethodPost, path: "/api/v1/matches/9/goals", body: `{"minute": 30, "team": "Celtic", "player": "Larsson"}`, want: http.StatusNotFound},
+		{name: "Finish game", method: http.MethodPost, path: "/api/v1/matches/1/finish", want: http.StatusOK},
+		{name: "Stats of retired team", method: http.MethodGet, path: "/api/v1/teams/Celtic/stats", want: http.StatusOK},
+		{name: "Head-to-head of retired team", method: http.MethodGet, path: "/api/v1/teams/Ajax/head-to-head/Celtic", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(app, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+
+	rec = doRequest(app, http.MethodGet, "/api/v1/teams/Celtic/stats", "")
+	var teamStats stats.TeamStats
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&teamStats))
+	assert.Equal(t, models.Countries("Celtic"), teamStats.Team)
+	assert.Equal(t, uint(1), teamStats.Won)
+}
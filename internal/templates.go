@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/i18n"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"html/template"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// templateFiles are the HTML templates compiled into the binary: the layout, the partials shared by pages and the pages.
+//
+//go:embed templates
+var templateFiles embed.FS
+
+const (
+	// templateLayout is the name of the template every page is executed through.
+	templateLayout = "layout"
+	// templatePageIndex is the page listing matches.
+	templatePageIndex = "index"
+	// templatePageLeague is the page of the league table.
+	templatePageLeague = "league"
+	// templatePageMatch is the page of a single match.
+	templatePageMatch = "match"
+	// templatePageLogin is the page of the login form.
+	templatePageLogin = "login"
+)
+
+// templatePages are the pages of the HTML UI. Every page is a file in the pages directory defining the title
+// and the content blocks of the layout.
+var templatePages = []string{templatePageIndex, templatePageLeague, templatePageMatch, templatePageLogin}
+
+// templateFuncs are the template functions that do not depend on the locale: score formats the score of a game,
+// duration a duration rounded to minutes, and flag returns the flag emoji of a team with an ISO code.
+// The functions of the locale, see i18n.Locale.Funcs, are added to them.
+var templateFuncs = template.FuncMap{
+	"score":    formatScore,
+	"duration": formatDuration,
+	"flag":     teamFlag,
+}
+
+// Templates represents the parsed pages of the HTML UI, one template set per page and locale.
+// In reload mode the pages are parsed again from their files on every execution, so changes to the files
+// show up without restarting. Templates is safe for concurrent use.
+type Templates struct {
+	fsys   fs.FS
+	reload bool
+	pages  map[string]map[*i18n.Locale]*template.Template
+}
+
+// NewTemplates parses the pages of the HTML UI from the provided file system, which has the layout.html file
+// and the partials and pages directories at its root. With reload set the pages are parsed again on every execution.
+func NewTemplates(fsys fs.FS, reload bool) (*Templates, error) {
+	x := &Templates{
+		fsys:   fsys,
+		reload: reload,
+		pages:  make(map[string]map[*i18n.Locale]*template.Template, len(templatePages)),
+	}
+	for _, page := range templatePages {
+		x.pages[page] = make(map[*i18n.Locale]*template.Template)
+		for _, locale := range i18n.Locales() {
+			tmpl, err := x.parse(page, locale)
+			if err != nil {
+				return nil, err
+			}
+			x.pages[page][locale] = tmpl
+		}
+	}
+	return x, nil
+}
+
+// embeddedTemplates parses the embedded templates once, on first use.
+var embeddedTemplates = sync.OnceValue(func() *Templates {
+	fsys, err := fs.Sub(templateFiles, "templates")
+	if err != nil {
+		panic(err)
+	}
+	templates, err := NewTemplates(fsys, false)
+	if err != nil {
+		panic(err)
+	}
+	return templates
+})
+
+// EmbeddedTemplates returns the templates compiled into the binary. They are parsed on the first call,
+// an error in them is a bug and panics.
+func EmbeddedTemplates() *Templates {
+	return embeddedTemplates()
+}
+
+// Execute executes the page with the provided data in the locale and writes the result to w.
+// The page is executed into a buffer first, so nothing is written if it fails.
+func (x *Templates) Execute(w io.Writer, page string, locale *i18n.Locale, data any) error {
+	tmpl, ok := x.pages[page][locale]
+	if !ok {
+		return fmt.Errorf("unknown page %s in locale %s", page, locale.Lang())
+	}
+	if x.reload {
+		var err error
+		if tmpl, err = x.parse(page, locale); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, templateLayout, data); err != nil {
+		return fmt.Errorf("execute page %s: %w", page, err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// parse parses the page together with the layout and the partials with the functions of the locale.
+func (x *Templates) parse(page string, locale *i18n.Locale) (*template.Template, error) {
+	tmpl, err := template.New(page).
+		Funcs(templateFuncs).
+		Funcs(locale.Funcs()).
+		ParseFS(x.fsys, "layout.html", "partials/*.html", "pages/"+page+".html")
+	if err != nil {
+		return nil, fmt.Errorf("parse page %s: %w", page, err)
+	}
+	return tmpl, nil
+}
+
+// formatScore returns the score of the game, e.g. "2 : 1".
+func formatScore(game *models.Game) string {
+	return fmt.Sprintf("%d : %d", game.HomeScore, game.AwayScore)
+}
+
+// formatDuration returns the duration rounded to minutes, e.g. "1h 05m" or "45m".
+func formatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	d = d.Round(time.Minute)
+	hours, minutes := int(d/time.Hour), int(d%time.Hour/time.Minute)
+	if hours > 0 {
+		return fmt.Sprintf("%s%dh %02dm", sign, hours, minutes)
+	}
+	return fmt.Sprintf("%s%dm", sign, minutes)
+}
+
+// teamFlag returns the flag emoji of the team made of the regional indicators of its ISO code,
+// an empty string for a team without a valid two-letter ISO code.
+func teamFlag(team models.Countries) string {
+	registered, ok := models.Teams().Team(team)
+	if !ok || len(registered.IsoCode) != 2 {
+		return ""
+	}
+	flag := make([]rune, 0, 2)
+	for _, letter := range registered.IsoCode {
+		if letter < 'A' || letter > 'Z' {
+			return ""
+		}
+		flag = append(flag, '\U0001F1E6'+letter-'A')
+	}
+	return string(flag)
+}
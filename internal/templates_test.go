@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"bytes"
+	"github.com/Marian2701/CodingExercise/internal/i18n"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testTemplateFiles returns minimal template files of all pages.
+func testTemplateFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"layout.html":          {Data: []byte(`{{define "layout"}}<title>{{template "title" .}}</title>{{template "content" .}}{{end}}`)},
+		"partials/shared.html": {Data: []byte(`{{define "greeting"}}{{t "matches.title"}}{{end}}`)},
+		"pages/index.html":     {Data: []byte(`{{define "title"}}index{{end}}{{define "content"}}{{template "greeting"}}{{end}}`)},
+		"pages/league.html":    {Data: []byte(`{{define "title"}}league{{end}}{{define "content"}}{{.}}{{end}}`)},
+		"pages/match.html":     {Data: []byte(`{{define "title"}}match{{end}}{{define "content"}}{{.}}{{end}}`)},
+		"pages/login.html":     {Data: []byte(`{{define "title"}}login{{end}}{{define "content"}}{{.}}{{end}}`)},
+	}
+}
+
+func TestTemplates_Execute(t *testing.T) {
+	tests := []struct {
+		name   string
+		reload bool
+		want   string
+	}{
+		{name: "Parsed once", reload: false, want: "<title>index</title>Матчи"},
+		{name: "Reloaded", reload: true, want: "<title>index</title>Матчи!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := testTemplateFiles()
+			templates, err := NewTemplates(files, tt.reload)
+			assert.NoError(t, err)
+
+			files["partials/shared.html"] = &fstest.MapFile{Data: []byte(`{{define "greeting"}}{{t "matches.title"}}!{{end}}`)}
+			var buf bytes.Buffer
+			assert.NoError(t, templates.Execute(&buf, templatePageIndex, i18n.Russian, nil))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTemplates_Errors(t *testing.T) {
+	files := testTemplateFiles()
+	files["pages/league.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{unknown}}{{end}}`)}
+	_, err := NewTemplates(files, false)
+	assert.Error(t, err)
+
+	templates, err := NewTemplates(testTemplateFiles(), false)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.Error(t, templates.Execute(&buf, "missing", i18n.English, nil))
+
+	// A page failing to execute writes nothing.
+	files = testTemplateFiles()
+	files["pages/league.html"] = &fstest.MapFile{Data: []byte(`{{define "title"}}league{{end}}{{define "content"}}{{.Missing}}{{end}}`)}
+	templates, err = NewTemplates(files, false)
+	assert.NoError(t, err)
+	assert.Error(t, templates.Execute(&buf, templatePageLeague, i18n.English, 42))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestEmbeddedTemplates(t *testing.T) {
+	var buf bytes.Buffer
+	err := EmbeddedTemplates().Execute(&buf, templatePageIndex, i18n.English, PageData{
+		Locale:        i18n.English,
+		Locales:       i18n.Locales(),
+		Countries:     models.Teams().Active(),
+		ActiveMatches: []*models.Game{{Id: 1, HomeTeam: models.Spain, AwayTeam: models.Brazil, HomeScore: 2, Status: models.StatusLive}},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Spain - Brazil | 2 : 0 | live")
+	assert.Contains(t, buf.String(), `<a href="?lang=ru">Русский</a>`)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	assert.Equal(t, "3 : 1", formatScore(&models.Game{HomeScore: 3, AwayScore: 1}))
+
+	assert.Equal(t, "45m", formatDuration(44*time.Minute+40*time.Second))
+	assert.Equal(t, "1h 05m", formatDuration(65*time.Minute))
+	assert.Equal(t, "-2h 00m", formatDuration(-2*time.Hour))
+
+	assert.Equal(t, "🇪🇸", teamFlag(models.Spain))
+	assert.Equal(t, "🇬🇧", teamFlag(models.UK))
+	assert.Equal(t, "", teamFlag("Narnia"))
+}
@@ -0,0 +1,161 @@
+package tournament
+
+import (
+	"cmp"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+)
+
+// Tie represents a knockout game between two teams. A team is NotACountry until it is known,
+// GameId and Winner are set once the game deciding the tie is finished.
+type Tie struct {
+	HomeTeam models.Countries `json:"home_team"`
+	AwayTeam models.Countries `json:"away_team"`
+	GameId   uint32           `json:"game_id,omitempty"`
+	Winner   models.Countries `json:"winner,omitempty"`
+}
+
+// Bracket represents a single-elimination knockout bracket. Rounds[0] holds the first round, the last round the final;
+// the winners of the ties 2i and 2i+1 of a round meet in the tie i of the next round.
+// A tie is decided by a finished game between its teams with an id above After, taking the penalty shootout
+// into account, so games played before the knockout stage, e.g. of the group stage, never decide a knockout tie.
+// Bracket is not safe for concurrent use.
+type Bracket struct {
+	Rounds [][]*Tie `json:"rounds"`
+	After  uint32   `json:"after"`
+}
+
+// NewBracket returns a new bracket of the teams, pairing the first with the second, the third with the fourth and so on.
+// Only games with an id above after decide ties. ErrInvalidBracket is returned unless the number of teams is a power
+// of two and at least two, or if a team is listed twice.
+func NewBracket(teams []models.Countries, after uint32) (*Bracket, error) {
+	n := len(teams)
+	if n < 2 || n&(n-1) != 0 {
+		return nil, ErrInvalidBracket
+	}
+	seen := make(map[models.Countries]bool, n)
+	for _, team := range teams {
+		if team == models.NotACountry || seen[team] {
+			return nil, ErrInvalidBracket
+		}
+		seen[team] = true
+	}
+
+	x := &Bracket{After: after}
+	for size := n / 2; size >= 1; size /= 2 {
+		round := make([]*Tie, 0, size)
+		for i := 0; i < size; i++ {
+			round = append(round, &Tie{})
+		}
+		x.Rounds = append(x.Rounds, round)
+	}
+	for i, tie := range x.Rounds[0] {
+		tie.HomeTeam, tie.AwayTeam = teams[2*i], teams[2*i+1]
+	}
+	return x, nil
+}
+
+// SeedFromGroups returns the teams of the first knockout round in bracket order: the groups are taken in pairs,
+// and the winner of each group meets the runner-up of the other group of its pair. Winners of a pair are put into
+// different halves of the bracket, so they can only meet again in the final rounds.
+// ErrInvalidBracket is returned if the number of groups is odd or a group has fewer than two teams.
+func SeedFromGroups(tables []Table) ([]models.Countries, error) {
+	if len(tables) == 0 || len(tables)%2 == 1 {
+		return nil, ErrInvalidBracket
+	}
+	for _, table := range tables {
+		if len(table.Rows) < 2 {
+			return nil, ErrInvalidBracket
+		}
+	}
+
+	top := make([]models.Countries, 0, len(tables))
+	bottom := make([]models.Countries, 0, len(tables))
+	for i := 0; i < len(tables); i += 2 {
+		first, second := tables[i].Rows, tables[i+1].Rows
+		top = append(top, first[0].Team, second[1].Team)
+		bottom = append(bottom, second[0].Team, first[1].Team)
+	}
+	return append(top, bottom...), nil
+}
+
+// Advance decides the open ties whose teams are known by the finished games and moves their winners to the next round.
+// Rounds are decided in order, so a winner moves on through every round whose game was already played.
+// Games ending without a winner, e.g. abandoned ones, do not decide a tie. It reports whether any tie was decided.
+func (x *Bracket) Advance(games []*models.Game) bool {
+	used := make(map[uint32]bool)
+	for _, round := range x.Rounds {
+		for _, tie := range round {
+			if tie.GameId != 0 {
+				used[tie.GameId] = true
+			}
+		}
+	}
+	// Later games are preferred, a replayed tie is decided by its last game.
+	candidates := slices.Clone(games)
+	slices.SortFunc(candidates, func(a, b *models.Game) int {
+		return cmp.Compare(b.Id, a.Id)
+	})
+
+	advanced := false
+	for r, round := range x.Rounds {
+		for i, tie := range round {
+			if tie.Winner != models.NotACountry || tie.HomeTeam == models.NotACountry || tie.AwayTeam == models.NotACountry {
+				continue
+			}
+			game := tie.decidingGame(candidates, x.After, used)
+			if game == nil {
+				continue
+			}
+			used[game.Id] = true
+			tie.GameId = game.Id
+			tie.Winner = game.Winner()
+			advanced = true
+
+			if r+1 < len(x.Rounds) {
+				next := x.Rounds[r+1][i/2]
+				if i%2 == 0 {
+					next.HomeTeam = tie.Winner
+				} else {
+					next.AwayTeam = tie.Winner
+				}
+			}
+		}
+	}
+	return advanced
+}
+
+// clone returns a deep copy of the bracket.
+func (x *Bracket) clone() *Bracket {
+	result := &Bracket{Rounds: make([][]*Tie, 0, len(x.Rounds)), After: x.After}
+	for _, round := range x.Rounds {
+		ties := make([]*Tie, 0, len(round))
+		for _, tie := range round {
+			copied := *tie
+			ties = append(ties, &copied)
+		}
+		result.Rounds = append(result.Rounds, ties)
+	}
+	return result
+}
+
+// Champion returns the winner of the final, NotACountry while the final is not decided.
+func (x *Bracket) Champion() models.Countries {
+	return x.Rounds[len(x.Rounds)-1][0].Winner
+}
+
+// decidingGame returns the first of the games that decides the tie: a game between the teams of the tie, in either
+// order, with an id above after that is not used by another tie and has a winner.
+func (x *Tie) decidingGame(games []*models.Game, after uint32, used map[uint32]bool) *models.Game {
+	for _, game := range games {
+		if game.Id <= after || used[game.Id] {
+			continue
+		}
+		between := (game.HomeTeam == x.HomeTeam && game.AwayTeam == x.AwayTeam) ||
+			(game.HomeTeam == x.AwayTeam && game.AwayTeam == x.HomeTeam)
+		if between && game.Winner() != models.NotACountry {
+			return game
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package tournament
+
+import "errors"
+
+var (
+	ErrInvalidGroup   = errors.New("invalid group")
+	ErrDuplicateTeam  = errors.New("team is in more than one group")
+	ErrInvalidBracket = errors.New("invalid knockout bracket")
+)
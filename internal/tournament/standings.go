@@ -0,0 +1,148 @@
+package tournament
+
+import (
+	"cmp"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"slices"
+)
+
+const (
+	// pointsForWin is the number of points a team gets for winning a group game.
+	pointsForWin = 3
+	// pointsForDraw is the number of points a team gets for a drawn group game.
+	pointsForDraw = 1
+)
+
+// Row represents the record of a team in a group table.
+type Row struct {
+	Team         models.Countries `json:"team"`
+	Played       uint             `json:"played"`
+	Won          uint             `json:"won"`
+	Drawn        uint             `json:"drawn"`
+	Lost         uint             `json:"lost"`
+	GoalsFor     uint             `json:"goals_for"`
+	GoalsAgainst uint             `json:"goals_against"`
+	Points       uint             `json:"points"`
+}
+
+// GoalDifference returns the goals scored by the team minus the goals conceded.
+func (x Row) GoalDifference() int {
+	return int(x.GoalsFor) - int(x.GoalsAgainst)
+}
+
+// Table represents the standings of a group, the leading team first.
+type Table struct {
+	Group string `json:"group"`
+	Rows  []Row  `json:"rows"`
+}
+
+// Table computes the standings of the group from the finished games played between its teams; other games,
+// including abandoned ones, are ignored. Every pair of teams plays once in the group, so only the first game,
+// by id, of each pair counts and a later one, e.g. when they meet again in the knockout stage, does not. Teams are ranked by points, goal difference and goals scored. Teams level
+// on all three are ranked by the same criteria applied to the games played between them only, and finally by name,
+// so the order is always deterministic.
+func (x Group) Table(games []*models.Game) Table {
+	var played []*models.Game
+	for _, game := range games {
+		if game.Status == models.StatusFinished && x.contains(game.HomeTeam) && x.contains(game.AwayTeam) {
+			played = append(played, game)
+		}
+	}
+	slices.SortFunc(played, func(a, b *models.Game) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	pairs := make(map[[2]models.Countries]bool, len(played))
+	played = slices.DeleteFunc(played, func(game *models.Game) bool {
+		pair := [2]models.Countries{min(game.HomeTeam, game.AwayTeam), max(game.HomeTeam, game.AwayTeam)}
+		if pairs[pair] {
+			return true
+		}
+		pairs[pair] = true
+		return false
+	})
+
+	rows := tally(x.Teams, played)
+	slices.SortFunc(rows, byRecord)
+
+	// Break the ties of teams level on points, goal difference and goals scored by their head-to-head games.
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for end < len(rows) && byRecord(rows[start], rows[end]) == 0 {
+			end++
+		}
+		if end-start > 1 {
+			headToHead(rows[start:end], played)
+		}
+		start = end
+	}
+	return Table{Group: x.Name, Rows: rows}
+}
+
+// tally returns the rows of the teams, in the order of the teams, computed from the games.
+func tally(teams []models.Countries, games []*models.Game) []Row {
+	rows := make([]Row, 0, len(teams))
+	index := make(map[models.Countries]int, len(teams))
+	for i, team := range teams {
+		rows = append(rows, Row{Team: team})
+		index[team] = i
+	}
+
+	for _, game := range games {
+		home, homeOk := index[game.HomeTeam]
+		away, awayOk := index[game.AwayTeam]
+		if !homeOk || !awayOk {
+			continue
+		}
+		rows[home].record(game.HomeScore, game.AwayScore)
+		rows[away].record(game.AwayScore, game.HomeScore)
+	}
+	return rows
+}
+
+// record adds the result of a game to the row.
+func (x *Row) record(scored, conceded uint) {
+	x.Played++
+	x.GoalsFor += scored
+	x.GoalsAgainst += conceded
+	switch {
+	case scored > conceded:
+		x.Won++
+		x.Points += pointsForWin
+	case scored == conceded:
+		x.Drawn++
+		x.Points += pointsForDraw
+	default:
+		x.Lost++
+	}
+}
+
+// headToHead orders the rows of teams level on their record by the games played between them, then by name.
+func headToHead(rows []Row, games []*models.Game) {
+	teams := make([]models.Countries, 0, len(rows))
+	for _, row := range rows {
+		teams = append(teams, row.Team)
+	}
+	mini := tally(teams, games)
+	rank := make(map[models.Countries]Row, len(mini))
+	for _, row := range mini {
+		rank[row.Team] = row
+	}
+
+	slices.SortFunc(rows, func(a, b Row) int {
+		if c := byRecord(rank[a.Team], rank[b.Team]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Team, b.Team)
+	})
+}
+
+// byRecord orders rows by points, goal difference and goals scored, the best record first.
+func byRecord(a, b Row) int {
+	if c := cmp.Compare(b.Points, a.Points); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(b.GoalDifference(), a.GoalDifference()); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.GoalsFor, a.GoalsFor)
+}
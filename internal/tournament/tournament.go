@@ -0,0 +1,235 @@
+// Package tournament computes World-Cup style tournaments on top of the games played on the board:
+// round-robin groups with their standings, and a knockout bracket seeded from the group standings.
+// Results are read from the finished games recorded by the tournament, e.g. by a RecordingScoreBase,
+// so the tournament itself keeps no scores.
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Group represents a named group of teams playing each other once.
+type Group struct {
+	Name  string             `json:"name" yaml:"name"`
+	Teams []models.Countries `json:"teams" yaml:"teams"`
+}
+
+// Config represents the content of a tournament config file.
+type Config struct {
+	Groups []Group `json:"groups" yaml:"groups"`
+}
+
+// Pairing represents a game of a group to be played in the round with the provided number, starting at 1.
+type Pairing struct {
+	Group    string           `json:"group"`
+	Round    int              `json:"round"`
+	HomeTeam models.Countries `json:"home_team"`
+	AwayTeam models.Countries `json:"away_team"`
+}
+
+// Tournament represents a tournament made of groups whose best teams advance to a knockout bracket.
+// Finished games are recorded by Record, which updates the standings and, once the knockout stage is seeded
+// by SeedKnockout, moves the winners of the decided ties through the bracket. Once seeded, the standings are
+// computed from the games played before the knockout stage only. Tournament is safe for concurrent use.
+type Tournament struct {
+	Groups   []Group
+	bracket  *Bracket
+	games    []*models.Game
+	recorded map[uint32]bool
+	lock     sync.RWMutex
+}
+
+// New returns a new tournament of the provided groups. ErrInvalidGroup is returned for a group without a name,
+// with fewer than two teams, with a name used by another group or with a team that is not the id of an active team,
+// and ErrDuplicateTeam if a team is in more than one group.
+func New(groups ...Group) (*Tournament, error) {
+	names := make(map[string]bool, len(groups))
+	teams := make(map[models.Countries]bool)
+	for _, group := range groups {
+		if group.Name == "" || names[group.Name] || len(group.Teams) < 2 {
+			return nil, ErrInvalidGroup
+		}
+		names[group.Name] = true
+		for _, team := range group.Teams {
+			if models.GetCountryFromString(string(team)) != team {
+				return nil, ErrInvalidGroup
+			}
+			if teams[team] {
+				return nil, ErrDuplicateTeam
+			}
+			teams[team] = true
+		}
+	}
+	return &Tournament{Groups: groups, recorded: make(map[uint32]bool)}, nil
+}
+
+// Load returns a new tournament of the groups of the config file at the provided path.
+// The file is JSON if its name ends with .json and YAML otherwise.
+func Load(path string) (*Tournament, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tournament config: %w", err)
+	}
+	var config Config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode tournament config %s: %w", path, err)
+	}
+
+	x, err := New(config.Groups...)
+	if err != nil {
+		return nil, fmt.Errorf("load tournament config %s: %w", path, err)
+	}
+	return x, nil
+}
+
+// Fixtures returns the round-robin fixtures of all groups, group by group.
+func (x *Tournament) Fixtures() []Pairing {
+	var result []Pairing
+	for _, group := range x.Groups {
+		result = append(result, group.Fixtures()...)
+	}
+	return result
+}
+
+// Record adds the game to the games of the tournament and reports whether it changed the standings of a group
+// or decided a knockout tie. Games recorded before, by id, are ignored.
+func (x *Tournament) Record(game *models.Game) bool {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if x.recorded[game.Id] {
+		return false
+	}
+	x.recorded[game.Id] = true
+	x.games = append(x.games, game)
+
+	changed := false
+	for _, group := range x.Groups {
+		if game.Status == models.StatusFinished && group.contains(game.HomeTeam) && group.contains(game.AwayTeam) &&
+			(x.bracket == nil || game.Id <= x.bracket.After) {
+			changed = true
+		}
+	}
+	if x.bracket != nil && x.bracket.Advance(x.games) {
+		changed = true
+	}
+	return changed
+}
+
+// Standings returns the tables of all groups computed from the recorded games, in the order of the groups.
+// Once the knockout stage is seeded, games after the cut-off of the bracket are left out.
+func (x *Tournament) Standings() []Table {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	if x.bracket == nil {
+		return x.tables(nil)
+	}
+	return x.tables(&x.bracket.After)
+}
+
+// Knockout returns a copy of the knockout bracket, nil until the knockout stage is seeded by SeedKnockout.
+func (x *Tournament) Knockout() *Bracket {
+	x.lock.RLock()
+	defer x.lock.RUnlock()
+
+	if x.bracket == nil {
+		return nil
+	}
+	return x.bracket.clone()
+}
+
+// SeedKnockout seeds the knockout bracket with the winners and runners-up of the groups, computed from the recorded
+// games up to and including after, and makes it the bracket of the tournament, replacing the one seeded before. Only games with an id above
+// after decide the ties, so after is the id of the last game played before the knockout stage, not necessarily
+// the last game of the groups. Recorded games deciding ties are taken into account right away.
+// The number of groups has to be even and the number of advancing teams a power of two, otherwise ErrInvalidBracket
+// is returned. It returns a copy of the bracket.
+func (x *Tournament) SeedKnockout(after uint32) (*Bracket, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	teams, err := SeedFromGroups(x.tables(&after))
+	if err != nil {
+		return nil, err
+	}
+	bracket, err := NewBracket(teams, after)
+	if err != nil {
+		return nil, err
+	}
+	bracket.Advance(x.games)
+	x.bracket = bracket
+	return bracket.clone(), nil
+}
+
+// tables returns the tables of all groups computed from the recorded games, only from those with an id up to
+// and including until if it is set. The lock must be held by the caller.
+func (x *Tournament) tables(until *uint32) []Table {
+	games := x.games
+	if until != nil {
+		games = make([]*models.Game, 0, len(x.games))
+		for _, game := range x.games {
+			if game.Id <= *until {
+				games = append(games, game)
+			}
+		}
+	}
+
+	result := make([]Table, 0, len(x.Groups))
+	for _, group := range x.Groups {
+		result = append(result, group.Table(games))
+	}
+	return result
+}
+
+// Fixtures returns the round-robin fixtures of the group generated by the circle method: every team plays every other
+// team once and, with an odd number of teams, one team rests in every round. Home and away are alternated
+// between rounds, so no team plays all its games at home.
+func (x Group) Fixtures() []Pairing {
+	teams := append([]models.Countries(nil), x.Teams...)
+	if len(teams)%2 == 1 {
+		teams = append(teams, models.NotACountry)
+	}
+	n := len(teams)
+
+	var result []Pairing
+	for round := 1; round < n; round++ {
+		for i := 0; i < n/2; i++ {
+			home, away := teams[i], teams[n-1-i]
+			if home == models.NotACountry || away == models.NotACountry {
+				continue
+			}
+			if (i == 0 && round%2 == 0) || (i > 0 && i%2 == 1) {
+				home, away = away, home
+			}
+			result = append(result, Pairing{Group: x.Name, Round: round, HomeTeam: home, AwayTeam: away})
+		}
+		// The first team stays in place, the others rotate by one position.
+		last := teams[n-1]
+		copy(teams[2:], teams[1:n-1])
+		teams[1] = last
+	}
+	return result
+}
+
+// contains reports whether the team is in the group.
+func (x Group) contains(team models.Countries) bool {
+	for _, member := range x.Teams {
+		if member == team {
+			return true
+		}
+	}
+	return false
+}
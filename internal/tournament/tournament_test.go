@@ -0,0 +1,264 @@
+package tournament
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []Group
+		wantErr error
+	}{
+		{
+			name:   "Valid groups",
+			groups: []Group{{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}}, {Name: "B", Teams: []models.Countries{models.Japan, models.USA}}},
+		},
+		{
+			name:    "Single team",
+			groups:  []Group{{Name: "A", Teams: []models.Countries{models.Spain}}},
+			wantErr: ErrInvalidGroup,
+		},
+		{
+			name:    "Missing name",
+			groups:  []Group{{Teams: []models.Countries{models.Spain, models.Brazil}}},
+			wantErr: ErrInvalidGroup,
+		},
+		{
+			name:    "Duplicate name",
+			groups:  []Group{{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}}, {Name: "A", Teams: []models.Countries{models.Japan, models.USA}}},
+			wantErr: ErrInvalidGroup,
+		},
+		{
+			name:    "Invalid team",
+			groups:  []Group{{Name: "A", Teams: []models.Countries{models.Spain, "Narnia"}}},
+			wantErr: ErrInvalidGroup,
+		},
+		{
+			name:    "Team in two groups",
+			groups:  []Group{{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}}, {Name: "B", Teams: []models.Countries{models.Spain, models.USA}}},
+			wantErr: ErrDuplicateTeam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.groups...)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestGroup_Fixtures(t *testing.T) {
+	tests := []struct {
+		name       string
+		teams      []models.Countries
+		wantGames  int
+		wantRounds int
+	}{
+		{
+			name:       "Even number of teams",
+			teams:      []models.Countries{models.Spain, models.Brazil, models.Germany, models.France},
+			wantGames:  6,
+			wantRounds: 3,
+		},
+		{
+			name:       "Odd number of teams",
+			teams:      []models.Countries{models.Spain, models.Brazil, models.Germany, models.France, models.Japan},
+			wantGames:  10,
+			wantRounds: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures := Group{Name: "A", Teams: tt.teams}.Fixtures()
+			assert.Equal(t, tt.wantGames, len(fixtures))
+
+			pairs := make(map[[2]models.Countries]bool)
+			rounds := make(map[int]map[models.Countries]bool)
+			homeGames := make(map[models.Countries]int)
+			for _, fixture := range fixtures {
+				assert.Equal(t, "A", fixture.Group)
+				pair := [2]models.Countries{min(fixture.HomeTeam, fixture.AwayTeam), max(fixture.HomeTeam, fixture.AwayTeam)}
+				assert.Equal(t, false, pairs[pair], "pair plays twice: %v", pair)
+				pairs[pair] = true
+
+				if rounds[fixture.Round] == nil {
+					rounds[fixture.Round] = make(map[models.Countries]bool)
+				}
+				for _, team := range []models.Countries{fixture.HomeTeam, fixture.AwayTeam} {
+					assert.Equal(t, false, rounds[fixture.Round][team], "team plays twice in round %d: %v", fixture.Round, team)
+					rounds[fixture.Round][team] = true
+				}
+				homeGames[fixture.HomeTeam]++
+			}
+			assert.Equal(t, tt.wantRounds, len(rounds))
+			for _, team := range tt.teams {
+				assert.Less(t, homeGames[team], len(tt.teams)-1, "team plays all games at home: %v", team)
+			}
+		})
+	}
+}
+
+func TestGroup_Table(t *testing.T) {
+	group := Group{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil, models.Germany, models.France}}
+	abandoned := &models.Game{Id: 7, HomeTeam: models.France, HomeScore: 5, AwayTeam: models.Germany, AwayScore: 0, Status: models.StatusAbandoned}
+	live := &models.Game{Id: 8, HomeTeam: models.France, HomeScore: 5, AwayTeam: models.Spain, AwayScore: 0, Status: models.StatusLive}
+	games := []*models.Game{
+		&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 3, AwayTeam: models.Germany, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 2, HomeTeam: models.Brazil, HomeScore: 1, AwayTeam: models.Spain, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 3, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.France, AwayScore: 1, Status: models.StatusFinished},
+		&models.Game{Id: 4, HomeTeam: models.Brazil, HomeScore: 4, AwayTeam: models.France, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 5, HomeTeam: models.Germany, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished},
+		&models.Game{Id: 6, HomeTeam: models.France, HomeScore: 1, AwayTeam: models.Germany, AwayScore: 1, Status: models.StatusFinished},
+		abandoned,
+		live,
+		&models.Game{Id: 9, HomeTeam: models.Japan, HomeScore: 9, AwayTeam: models.Spain, AwayScore: 0, Status: models.StatusFinished},
+	}
+
+	table := group.Table(games)
+	assert.Equal(t, "A", table.Group)
+	teams := make([]models.Countries, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		teams = append(teams, row.Team)
+	}
+	// Spain and Brazil are level on points, goal difference and goals scored, Brazil won their game.
+	assert.Equal(t, []models.Countries{models.Brazil, models.Spain, models.Germany, models.France}, teams)
+	assert.Equal(t, Row{Team: models.Brazil, Played: 3, Won: 2, Lost: 1, GoalsFor: 5, GoalsAgainst: 2, Points: 6}, table.Rows[0])
+	assert.Equal(t, Row{Team: models.France, Played: 3, Drawn: 1, Lost: 2, GoalsFor: 2, GoalsAgainst: 7, Points: 1}, table.Rows[3])
+	assert.Equal(t, -5, table.Rows[3].GoalDifference())
+
+	// Without games the teams are ordered by name.
+	table = group.Table(nil)
+	assert.Equal(t, models.Brazil, table.Rows[0].Team)
+	assert.Equal(t, models.Spain, table.Rows[3].Team)
+}
+
+func TestTournament_Knockout(t *testing.T) {
+	tournament, err := New(
+		Group{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}},
+		Group{Name: "B", Teams: []models.Countries{models.Germany, models.France}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tournament.Fixtures()))
+	assert.Nil(t, tournament.Knockout())
+
+	assert.Equal(t, true, tournament.Record(&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished}))
+	// A friendly of the teams meeting in the first knockout round does not decide their tie.
+	assert.Equal(t, false, tournament.Record(&models.Game{Id: 2, HomeTeam: models.Spain, HomeScore: 0, AwayTeam: models.Germany, AwayScore: 3, Status: models.StatusFinished}))
+	// A knockout game started before the last group game was finished decides its tie all the same.
+	assert.Equal(t, false, tournament.Record(&models.Game{Id: 3, HomeTeam: models.France, HomeScore: 1, AwayTeam: models.Brazil, AwayScore: 2, Status: models.StatusFinished}))
+	assert.Equal(t, true, tournament.Record(&models.Game{Id: 4, HomeTeam: models.Germany, HomeScore: 0, AwayTeam: models.France, AwayScore: 1, Status: models.StatusFinished}))
+	assert.Equal(t, false, tournament.Record(&models.Game{Id: 4, HomeTeam: models.Germany, HomeScore: 0, AwayTeam: models.France, AwayScore: 1, Status: models.StatusFinished}))
+	standings := tournament.Standings()
+	assert.Equal(t, models.Spain, standings[0].Rows[0].Team)
+	assert.Equal(t, models.France, standings[1].Rows[0].Team)
+
+	bracket, err := tournament.SeedKnockout(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(bracket.Rounds))
+	assert.Equal(t, Tie{HomeTeam: models.Spain, AwayTeam: models.Germany}, *bracket.Rounds[0][0])
+	assert.Equal(t, Tie{HomeTeam: models.France, AwayTeam: models.Brazil, GameId: 3, Winner: models.Brazil}, *bracket.Rounds[0][1])
+
+	assert.Equal(t, true, tournament.Record(&models.Game{Id: 5, HomeTeam: models.Germany, HomeScore: 2, AwayTeam: models.Spain, AwayScore: 1, Status: models.StatusFinished}))
+	shootout := &models.Game{Id: 6, HomeTeam: models.Germany, HomeScore: 1, AwayTeam: models.Brazil, AwayScore: 1, Status: models.StatusFinished, PenaltyScore: &models.Score{Home: 3, Away: 4}}
+	assert.Equal(t, true, tournament.Record(shootout))
+
+	bracket = tournament.Knockout()
+	assert.Equal(t, Tie{HomeTeam: models.Spain, AwayTeam: models.Germany, GameId: 5, Winner: models.Germany}, *bracket.Rounds[0][0])
+	assert.Equal(t, Tie{HomeTeam: models.Germany, AwayTeam: models.Brazil, GameId: 6, Winner: models.Brazil}, *bracket.Rounds[1][0])
+	assert.Equal(t, models.Brazil, bracket.Champion())
+
+	// The returned bracket is a copy.
+	bracket.Rounds[1][0].Winner = models.Spain
+	assert.Equal(t, models.Brazil, tournament.Knockout().Champion())
+}
+
+func TestTournament_Knockout_GroupRematch(t *testing.T) {
+	tournament, err := New(
+		Group{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}},
+		Group{Name: "B", Teams: []models.Countries{models.Germany, models.France}},
+	)
+	assert.NoError(t, err)
+	tournament.Record(&models.Game{Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished})
+	tournament.Record(&models.Game{Id: 2, HomeTeam: models.Germany, HomeScore: 0, AwayTeam: models.France, AwayScore: 1, Status: models.StatusFinished})
+	_, err = tournament.SeedKnockout(2)
+	assert.NoError(t, err)
+	tournament.Record(&models.Game{Id: 3, HomeTeam: models.Spain, HomeScore: 1, AwayTeam: models.Germany, AwayScore: 0, Status: models.StatusFinished})
+	tournament.Record(&models.Game{Id: 4, HomeTeam: models.France, HomeScore: 0, AwayTeam: models.Brazil, AwayScore: 1, Status: models.StatusFinished})
+
+	// The winner and the runner-up of group A meet again in the final, which is not a game of the group.
+	final := &models.Game{Id: 5, HomeTeam: models.Spain, HomeScore: 0, AwayTeam: models.Brazil, AwayScore: 3, Status: models.StatusFinished}
+	assert.Equal(t, true, tournament.Record(final))
+	assert.Equal(t, models.Brazil, tournament.Knockout().Champion())
+	standings := tournament.Standings()
+	assert.Equal(t, Row{Team: models.Spain, Played: 1, Won: 1, GoalsFor: 2, Points: 3}, standings[0].Rows[0])
+	assert.Equal(t, Row{Team: models.Brazil, Played: 1, Lost: 1, GoalsAgainst: 2}, standings[0].Rows[1])
+
+	// Without a cut-off the group table still counts only the first game of every pair of teams.
+	group := tournament.Groups[0]
+	table := group.Table([]*models.Game{final, {Id: 1, HomeTeam: models.Spain, HomeScore: 2, AwayTeam: models.Brazil, AwayScore: 0, Status: models.StatusFinished}})
+	assert.Equal(t, models.Spain, table.Rows[0].Team)
+	assert.Equal(t, uint(1), table.Rows[0].Played)
+}
+
+func TestSeedKnockout_InvalidBracket(t *testing.T) {
+	tournament, err := New(
+		Group{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}},
+		Group{Name: "B", Teams: []models.Countries{models.Germany, models.France}},
+		Group{Name: "C", Teams: []models.Countries{models.Japan, models.USA}},
+	)
+	assert.NoError(t, err)
+	_, err = tournament.SeedKnockout(0)
+	assert.ErrorIs(t, err, ErrInvalidBracket)
+
+	_, err = NewBracket([]models.Countries{models.Spain, models.Brazil, models.Japan}, 0)
+	assert.ErrorIs(t, err, ErrInvalidBracket)
+	_, err = NewBracket([]models.Countries{models.Spain, models.Spain}, 0)
+	assert.ErrorIs(t, err, ErrInvalidBracket)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{
+			name: "YAML",
+			file: "tournament.yaml",
+			data: "groups:\n  - name: A\n    teams: [Spain, Brazil]\n  - name: B\n    teams: [Germany, France]\n",
+		},
+		{
+			name: "JSON",
+			file: "tournament.json",
+			data: `{"groups": [{"name": "A", "teams": ["Spain", "Brazil"]}, {"name": "B", "teams": ["Germany", "France"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			assert.NoError(t, os.WriteFile(path, []byte(tt.data), 0o644))
+
+			x, err := Load(path)
+			assert.NoError(t, err)
+			assert.Equal(t, []Group{
+				{Name: "A", Teams: []models.Countries{models.Spain, models.Brazil}},
+				{Name: "B", Teams: []models.Countries{models.Germany, models.France}},
+			}, x.Groups)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "tournament.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("groups:\n  - name: A\n    teams: [Spain]\n"), 0o644))
+	_, err := Load(path)
+	assert.ErrorIs(t, err, ErrInvalidGroup)
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"github.com/Marian2701/CodingExercise/internal/models"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"time"
+)
+
+const (
+	// wsWriteTimeout is the time allowed to write a single message to a WebSocket client.
+	wsWriteTimeout = 10 * time.Second
+	// wsPongTimeout is the time allowed to read the next pong message from a WebSocket client.
+	wsPongTimeout = 60 * time.Second
+	// wsPingInterval is the interval of ping messages sent to WebSocket clients, it must be less than wsPongTimeout.
+	wsPingInterval = wsPongTimeout * 9 / 10
+	// wsMaxMessageSize is the maximum size of a message accepted from a WebSocket client.
+	wsMaxMessageSize = 1024
+)
+
+const (
+	// wsActionSubscribe is the action of a client message subscribing to a match id or a team.
+	wsActionSubscribe = "subscribe"
+	// wsActionUnsubscribe is the action of a client message removing a subscription to a match id or a team.
+	wsActionUnsubscribe = "unsubscribe"
+)
+
+// wsClientMessage defines the message accepted from WebSocket clients.
+// Either MatchId or Team has to be provided.
+type wsClientMessage struct {
+	Action  string `json:"action"`
+	MatchId uint32 `json:"match_id"`
+	Team    string `json:"team"`
+}
+
+// wsUpgrader upgrades HTTP connections to the WebSocket protocol.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// handleWebSocket upgrades the connection to the WebSocket protocol and connects it to the hub.
+// Clients send subscribe/unsubscribe messages for match ids or teams and receive score deltas of the subscribed games.
+func (a *App) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		a.logger.Println("failed to upgrade connection: ", err)
+		return
+	}
+
+	client := a.hub.Connect()
+	go a.writeWebSocket(conn, client)
+	a.readWebSocket(conn, client)
+}
+
+// readWebSocket applies subscription messages of the client until the connection is closed.
+func (a *App) readWebSocket(conn *websocket.Conn, client *HubClient) {
+	defer a.hub.Disconnect(client)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		var message wsClientMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				a.logger.Println("failed to read websocket message: ", err)
+			}
+			return
+		}
+		a.hub.Reply(client, a.applyWebSocketMessage(client, message))
+	}
+}
+
+// applyWebSocketMessage updates the subscriptions of the client and returns the acknowledgement or error reply.
+func (a *App) applyWebSocketMessage(client *HubClient, message wsClientMessage) HubMessage {
+	if message.Action != wsActionSubscribe && message.Action != wsActionUnsubscribe {
+		return HubMessage{Type: "error", Error: "unknown action"}
+	}
+
+	reply := HubMessage{Type: message.Action + "d"}
+	switch {
+	case message.Team != "":
+		team, err := models.ResolveTeam(message.Team)
+		if err != nil {
+			return HubMessage{Type: "error", Error: err.Error()}
+		}
+		if message.Action == wsActionSubscribe {
+			a.hub.SubscribeTeam(client, team)
+		} else {
+			a.hub.UnsubscribeTeam(client, team)
+		}
+		reply.Team = team
+	case message.MatchId != 0:
+		if message.Action == wsActionSubscribe {
+			a.hub.SubscribeMatch(client, message.MatchId)
+		} else {
+			a.hub.UnsubscribeMatch(client, message.MatchId)
+		}
+		reply.MatchId = message.MatchId
+	default:
+		return HubMessage{Type: "error", Error: "match_id or team is required"}
+	}
+	return reply
+}
+
+// writeWebSocket writes messages queued for the client and pings it until the client is disconnected by the hub.
+func (a *App) writeWebSocket(conn *websocket.Conn, client *HubClient) {
+	ping := time.NewTicker(wsPingInterval)
+	defer func() {
+		ping.Stop()
+		_ = conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-client.Send:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				a.hub.Disconnect(client)
+				return
+			}
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				a.hub.Disconnect(client)
+				return
+			}
+		}
+	}
+}